@@ -2,35 +2,181 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/kubaliski/task-cli/internal/commands"
+	"github.com/kubaliski/task-cli/internal/config"
+	"github.com/kubaliski/task-cli/internal/daemon"
+	"github.com/kubaliski/task-cli/internal/hooks"
+	"github.com/kubaliski/task-cli/internal/task"
+	"github.com/kubaliski/task-cli/pkg/taskcli"
+	"io/fs"
 	"os"
-	"task-cli/internal/commands"
-	"task-cli/internal/task"
+	"time"
 )
 
+// Exit codes, one per class of error
+const (
+	exitError    = 1 // Any other error
+	exitUsage    = 2 // Unknown command, missing or invalid arguments
+	exitNotFound = 3 // The task doesn't exist
+	exitInvalid  = 4 // Invalid value: title, priority, date, duration, filter...
+	exitState    = 5 // The state of the task doesn't allow the operation
+	exitIO       = 6 // Reading or writing files failed
+)
+
+// errorClass is the class of an error, with its name for the JSON output
+type errorClass struct {
+	name     string
+	exitCode int
+	errs     []error
+}
+
+// Classes of the errors not matched by their value
+var (
+	genericError = errorClass{name: "error", exitCode: exitError}
+	ioError      = errorClass{name: "io", exitCode: exitIO}
+)
+
+// errorClasses are checked in order, the first one with a matching error wins
+var errorClasses = []errorClass{
+	{"not_found", exitNotFound, []error{task.ErrNotFound}},
+	{"usage", exitUsage, []error{commands.ErrUnknownCommand, commands.ErrUsage}},
+	{"invalid", exitInvalid, []error{
+		task.ErrTitleRequired, task.ErrInvalidPriority, task.ErrInvalidDate,
+		task.ErrInvalidTimeOrder, task.ErrInvalidDuration, task.ErrInvalidFilter,
+		task.ErrInvalidID, task.ErrInvalidValue, config.ErrInvalidConfig,
+	}},
+	{"state", exitState, []error{
+		task.ErrAlreadyCompleted, task.ErrNotCompleted, task.ErrTimerRunning, task.ErrNoTimer,
+		commands.ErrDaemonRunning,
+	}},
+}
+
+// errorOutput is the error printed with --json
+type errorOutput struct {
+	Error    string `json:"error"`
+	Class    string `json:"class"`
+	ExitCode int    `json:"exit_code"`
+}
+
 func main() {
-	tm := task.NewTaskManager()
-	if err := tm.LoadTasks(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading tasks: %v\n", err)
-		os.Exit(1)
+	opts, args, err := commands.ParseOptions(os.Args[1:])
+	jsonErrors := opts.JSONErrors
+	if err != nil {
+		exit(err, jsonErrors, genericError)
 	}
+	if opts.DataDir != "" {
+		task.SetDataDir(opts.DataDir)
+	}
+
+	dataDir, err := task.DataDir()
+	if err != nil {
+		exit(fmt.Errorf("error finding data directory: %w", err), jsonErrors, ioError)
+	}
+	cfg, err := config.Load(dataDir)
+	if err == nil {
+		err = cfg.ApplyPriorities()
+	}
+	if err != nil {
+		exit(fmt.Errorf("error loading configuration: %w", err), jsonErrors, genericError)
+	}
+	task.SetUrgencyWeights(cfg.UrgencyWeights())
+	task.SetPriorityAging(cfg.PriorityAging())
 
-	commander := commands.NewCommander(tm)
+	tm, err := openTaskManager()
+	if err != nil {
+		exit(fmt.Errorf("error loading tasks: %w", err), jsonErrors, ioError)
+	}
+
+	runner, err := startHooks(tm, dataDir, cfg)
+	if err != nil {
+		exit(fmt.Errorf("error loading hooks: %w", err), jsonErrors, genericError)
+	}
+
+	commander := commands.NewCommander(tm, opts)
 
 	// Check if there are any arguments, if not, show help
-	if len(os.Args) < 2 {
+	if len(args) == 0 {
 		if err := commander.Execute("help", []string{}); err != nil {
-			fmt.Fprintf(os.Stderr, "Error showing help: %v\n", err)
-			os.Exit(1)
+			exit(fmt.Errorf("error showing help: %w", err), jsonErrors, genericError)
 		}
 		os.Exit(0)
 	}
 
-	command := os.Args[1]
-	args := os.Args[2:]
+	err = commander.Execute(args[0], args[1:])
+	// The hooks fired by the command finish before exiting
+	runner.Wait()
+	if err != nil {
+		exit(err, jsonErrors, genericError)
+	}
+}
+
+// openTaskManager connects to the daemon when it's running, otherwise it
+// loads the tasks from the data directory
+func openTaskManager() (task.ITaskManager, error) {
+	if dataDir, err := task.DataDir(); err == nil {
+		if client, err := daemon.Dial(daemon.SocketPath(dataDir)); err == nil {
+			return client, nil
+		}
+	}
+
+	m, err := taskcli.Open(context.Background(), "")
+	if err != nil {
+		return nil, err
+	}
+	return m.TaskManager(), nil
+}
+
+// startHooks fires the configured hooks on the changes made by this process
+// and on the overdue tasks. When the daemon is running it fires them itself.
+func startHooks(tm task.ITaskManager, dataDir string, cfg config.Config) (*hooks.Runner, error) {
+	runner := hooks.New(dataDir, cfg.Hooks)
+	if _, remote := tm.(*daemon.Client); remote {
+		return runner, nil
+	}
+
+	runner.Attach(tm)
+	if err := runner.CheckOverdue(tm); err != nil {
+		return nil, err
+	}
+	// Only long running commands like daemon and serve live long enough
+	go runner.WatchOverdue(context.Background(), tm, time.Minute)
+	return runner, nil
+}
 
-	if err := commander.Execute(command, args); err != nil {
+// exit prints the error and exits with the code of its class. Errors
+// without a known class use the fallback class.
+func exit(err error, jsonOutput bool, fallback errorClass) {
+	class, ok := classify(err)
+	if !ok {
+		class = fallback
+	}
+
+	if jsonOutput {
+		data, _ := json.Marshal(errorOutput{Error: err.Error(), Class: class.name, ExitCode: class.exitCode})
+		fmt.Fprintln(os.Stderr, string(data))
+	} else {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
 	}
+	os.Exit(class.exitCode)
+}
+
+// classify returns the class of an error
+func classify(err error) (errorClass, bool) {
+	for _, class := range errorClasses {
+		for _, target := range class.errs {
+			if errors.Is(err, target) {
+				return class, true
+			}
+		}
+	}
+
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return ioError, true
+	}
+	return errorClass{}, false
 }
@@ -0,0 +1,59 @@
+// Example of a program that embeds task-cli: it adds a task to a data
+// directory, shows the pending tasks in a table and reports the changes it makes.
+//
+//	go run ./examples/embed -dir /tmp/tasks
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/kubaliski/task-cli/pkg/taskcli"
+	"os"
+)
+
+func main() {
+	dir := flag.String("dir", "", "Data directory (default: ~/.task-cli)")
+	flag.Parse()
+
+	if err := run(context.Background(), *dir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, dir string) error {
+	m, err := taskcli.Open(ctx, dir)
+	if err != nil {
+		return err
+	}
+
+	cancel := m.Subscribe(func(e taskcli.Event) {
+		fmt.Printf("event: task %d %s\n", e.Task.ID, e.Action)
+	})
+	defer cancel()
+
+	t, err := m.Add(ctx, "Try the task-cli library", taskcli.PriorityHigh)
+	if err != nil {
+		return err
+	}
+	if err := m.SetTags(ctx, t.ID, []string{"example"}); err != nil {
+		return err
+	}
+
+	if _, err := m.Get(ctx, 0); errors.Is(err, taskcli.ErrNotFound) {
+		fmt.Println("task 0 doesn't exist:", err)
+	}
+
+	tasks, err := m.List(ctx, taskcli.ListOptions{Where: "tag:example", ByPriority: true})
+	if err != nil {
+		return err
+	}
+	p := taskcli.NewPresenter(os.Stdout, taskcli.PresenterOptions{Color: taskcli.ColorAuto})
+	if err := p.PrintTaskTable(tasks); err != nil {
+		return err
+	}
+
+	return m.Save(ctx)
+}
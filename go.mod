@@ -1,3 +1,3 @@
-module task-cli
+module github.com/kubaliski/task-cli
 
 go 1.23.4
@@ -0,0 +1,417 @@
+// Package backup creates and restores compressed archives of the data directory
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// manifestName is the name of the manifest inside the archive
+	manifestName = "manifest.json"
+	// dataPrefix is the directory of the data files inside the archive
+	dataPrefix = "data/"
+	// formatVersion is the version of the archive layout
+	formatVersion = 1
+)
+
+// Manifest describes the content of a backup
+type Manifest struct {
+	Version   int        `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	Files     []FileInfo `json:"files"`
+}
+
+// FileInfo describes a data file in the backup
+type FileInfo struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Archive is a backup read in memory whose checksums have been verified
+type Archive struct {
+	Manifest Manifest
+	files    map[string][]byte
+}
+
+// FileDiff summarizes the changes a restore makes to a data file
+type FileDiff struct {
+	Name    string
+	Status  string // "new", "changed", "unchanged" or "removed"
+	Added   int
+	Removed int
+	Changed int
+	IsTasks bool
+}
+
+// Create writes a gzipped tar archive with every regular file of dataDir
+// and a manifest with their checksums
+func Create(dataDir string, w io.Writer) (Manifest, error) {
+	manifest := Manifest{Version: formatVersion, CreatedAt: time.Now()}
+
+	files, err := dataFiles(dataDir)
+	if err != nil {
+		return manifest, err
+	}
+
+	contents := make(map[string][]byte)
+	for _, name := range files {
+		data, err := os.ReadFile(filepath.Join(dataDir, name))
+		if err != nil {
+			return manifest, err
+		}
+		contents[name] = data
+		manifest.Files = append(manifest.Files, FileInfo{
+			Name:   name,
+			Size:   int64(len(data)),
+			SHA256: checksum(data),
+		})
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, err
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	if err := writeEntry(tw, manifestName, manifestData); err != nil {
+		return manifest, err
+	}
+	for _, name := range files {
+		if err := writeEntry(tw, dataPrefix+name, contents[name]); err != nil {
+			return manifest, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return manifest, err
+	}
+	return manifest, gw.Close()
+}
+
+// Read reads a backup and verifies it against its manifest
+func Read(r io.Reader) (*Archive, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a backup file: %v", err)
+	}
+	defer gr.Close()
+
+	a := &Archive{files: make(map[string][]byte)}
+	var manifestData []byte
+
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("corrupted backup: %v", err)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("corrupted backup: %v", err)
+		}
+
+		switch {
+		case header.Name == manifestName:
+			manifestData = data
+		case strings.HasPrefix(header.Name, dataPrefix):
+			name := strings.TrimPrefix(header.Name, dataPrefix)
+			if !validName(name) {
+				return nil, fmt.Errorf("invalid file name in backup: %s", header.Name)
+			}
+			a.files[name] = data
+		}
+	}
+
+	if manifestData == nil {
+		return nil, fmt.Errorf("backup has no manifest")
+	}
+	if err := json.Unmarshal(manifestData, &a.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %v", err)
+	}
+	if a.Manifest.Version > formatVersion {
+		return nil, fmt.Errorf("backup version %d is newer than supported (%d)", a.Manifest.Version, formatVersion)
+	}
+
+	if err := a.verify(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// verify checks the files against the manifest and that the task files
+// can be read
+func (a *Archive) verify() error {
+	if len(a.files) != len(a.Manifest.Files) {
+		return fmt.Errorf("backup has %d files but its manifest lists %d", len(a.files), len(a.Manifest.Files))
+	}
+
+	for _, info := range a.Manifest.Files {
+		data, ok := a.files[info.Name]
+		if !ok {
+			return fmt.Errorf("file %s listed in the manifest is missing", info.Name)
+		}
+		if int64(len(data)) != info.Size || checksum(data) != info.SHA256 {
+			return fmt.Errorf("checksum mismatch for %s", info.Name)
+		}
+		if isTaskFile(info.Name) {
+			if _, err := parseTasks(data); err != nil {
+				return fmt.Errorf("invalid task file %s: %v", info.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Diff compares the backup with the current content of dataDir
+func (a *Archive) Diff(dataDir string) ([]FileDiff, error) {
+	current, err := dataFiles(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for _, name := range current {
+		names[name] = true
+	}
+	for name := range a.files {
+		names[name] = true
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var diffs []FileDiff
+	for _, name := range sorted {
+		restored, inBackup := a.files[name]
+		existing, err := os.ReadFile(filepath.Join(dataDir, name))
+		exists := err == nil
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		diff := FileDiff{Name: name, IsTasks: isTaskFile(name)}
+		switch {
+		case !inBackup:
+			diff.Status = "removed"
+		case !exists:
+			diff.Status = "new"
+		case bytes.Equal(existing, restored):
+			diff.Status = "unchanged"
+		default:
+			diff.Status = "changed"
+		}
+
+		if diff.IsTasks && diff.Status != "unchanged" {
+			before, _ := parseTasks(existing)
+			after, _ := parseTasks(restored)
+			diff.Added, diff.Removed, diff.Changed = diffTasks(before, after)
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+// rename moves a file, replaced by the tests to make a restore fail
+var rename = os.Rename
+
+// Restore replaces the data files of dataDir with the ones in the backup.
+// Every file is written to a temporary file first. The current data files
+// are then moved aside to a hidden directory and the new ones moved in: if
+// any step fails the files moved so far are put back, so the directory
+// keeps either its data or the backup, never a mix of both.
+func (a *Archive) Restore(dataDir string) error {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return err
+	}
+
+	// Stage every file before touching the current data
+	staged := make(map[string]string)
+	cleanup := func() {
+		for _, tmp := range staged {
+			os.Remove(tmp)
+		}
+	}
+	for name, data := range a.files {
+		tmp, err := os.CreateTemp(dataDir, ".restore-*")
+		if err != nil {
+			cleanup()
+			return err
+		}
+		staged[name] = tmp.Name()
+		if _, err := tmp.Write(data); err != nil {
+			tmp.Close()
+			cleanup()
+			return err
+		}
+		if err := tmp.Close(); err != nil {
+			cleanup()
+			return err
+		}
+	}
+	defer cleanup()
+
+	current, err := dataFiles(dataDir)
+	if err != nil {
+		return err
+	}
+	aside, err := os.MkdirTemp(dataDir, ".restore-old-*")
+	if err != nil {
+		return err
+	}
+
+	var movedAside, movedIn []string
+	rollback := func(cause error) error {
+		var errs []error
+		for _, name := range movedIn {
+			if err := os.Remove(filepath.Join(dataDir, name)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		for _, name := range movedAside {
+			if err := rename(filepath.Join(aside, name), filepath.Join(dataDir, name)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("%w; the data couldn't be put back, it is in %s: %w", cause, aside, errors.Join(errs...))
+		}
+		os.RemoveAll(aside)
+		return cause
+	}
+
+	for _, name := range current {
+		if err := rename(filepath.Join(dataDir, name), filepath.Join(aside, name)); err != nil {
+			return rollback(err)
+		}
+		movedAside = append(movedAside, name)
+	}
+
+	names := make([]string, 0, len(staged))
+	for name := range staged {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := rename(staged[name], filepath.Join(dataDir, name)); err != nil {
+			return rollback(err)
+		}
+		delete(staged, name)
+		movedIn = append(movedIn, name)
+	}
+
+	// The backup is in place, a leftover hidden directory is harmless
+	os.RemoveAll(aside)
+	return nil
+}
+
+// dataFiles returns the names of the regular files in dataDir, hidden files
+// and directories are skipped
+func dataFiles(dataDir string) ([]string, error) {
+	entries, err := os.ReadDir(dataDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && validName(entry.Name()) {
+			files = append(files, entry.Name())
+		}
+	}
+	return files, nil
+}
+
+// validName reports whether a name is a plain, visible file name
+func validName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, ".") && !strings.ContainsAny(name, `/\`)
+}
+
+// isTaskFile reports whether the file holds a list of tasks
+func isTaskFile(name string) bool {
+	for _, f := range task.TaskFiles {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTasks decodes a task file
+func parseTasks(data []byte) ([]task.Task, error) {
+	var tasks []task.Task
+	if len(data) == 0 {
+		return tasks, nil
+	}
+	err := json.Unmarshal(data, &tasks)
+	return tasks, err
+}
+
+// diffTasks counts the tasks added, removed and changed from before to after
+func diffTasks(before, after []task.Task) (added, removed, changed int) {
+	old := make(map[int][]byte)
+	for _, t := range before {
+		data, _ := json.Marshal(t)
+		old[t.ID] = data
+	}
+
+	for _, t := range after {
+		data, _ := json.Marshal(t)
+		previous, ok := old[t.ID]
+		switch {
+		case !ok:
+			added++
+		case !bytes.Equal(previous, data):
+			changed++
+		}
+		delete(old, t.ID)
+	}
+	return added, len(old), changed
+}
+
+// writeEntry adds a file to the tar archive
+func writeEntry(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// checksum returns the hex encoded SHA-256 of the data
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,169 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// writeFiles replaces the content of dir with the files
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	names, err := dataFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readFiles returns the data files of dir, failing when a hidden file was
+// left behind
+func readFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, entry := range entries {
+		if !validName(entry.Name()) {
+			t.Errorf("%s left in the data directory", entry.Name())
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[entry.Name()] = string(data)
+	}
+	return files
+}
+
+func TestRestore(t *testing.T) {
+	backedUp := map[string]string{
+		"tasks.json":  `[{"id":1,"title":"backed up"}]`,
+		"config.json": `{"backed_up":true}`,
+	}
+	current := map[string]string{
+		"tasks.json":   `[{"id":1,"title":"current"},{"id":2,"title":"new"}]`,
+		"archive.json": `[]`,
+	}
+
+	tests := []struct {
+		name   string
+		failAt int // Rename call that fails, 0 for none
+		want   map[string]string
+	}{
+		{"success", 0, backedUp},
+		{"moving the current files aside", 1, current},
+		{"moving the last current file aside", 2, current},
+		{"moving the first backup file in", 3, current},
+		{"moving the last backup file in", 4, current},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, backedUp)
+			var buf bytes.Buffer
+			if _, err := Create(dir, &buf); err != nil {
+				t.Fatal(err)
+			}
+			archive, err := Read(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeFiles(t, dir, current)
+
+			calls := 0
+			rename = func(from, to string) error {
+				calls++
+				if calls == tt.failAt {
+					return errors.New("rename failed")
+				}
+				return os.Rename(from, to)
+			}
+			defer func() { rename = os.Rename }()
+
+			err = archive.Restore(dir)
+			if (err != nil) != (tt.failAt > 0) {
+				t.Fatalf("Restore error = %v", err)
+			}
+			if got := readFiles(t, dir); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got files %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// archiveWith writes a backup holding the entries, manifest included
+func archiveWith(t *testing.T, entries map[string]string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for name, content := range entries {
+		if err := writeEntry(tw, name, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestReadVerifies(t *testing.T) {
+	tasks := `[{"id":1,"title":"a"}]`
+	manifest := func(name, content string) string {
+		return `{"version":1,"files":[{"name":"` + name + `","size":` + strconv.Itoa(len(content)) +
+			`,"sha256":"` + checksum([]byte(content)) + `"}]}`
+	}
+
+	tests := []struct {
+		name    string
+		entries map[string]string
+		wantErr string
+	}{
+		{"valid", map[string]string{manifestName: manifest("tasks.json", tasks), dataPrefix + "tasks.json": tasks}, ""},
+		{"no manifest", map[string]string{dataPrefix + "tasks.json": tasks}, "no manifest"},
+		{"changed file", map[string]string{manifestName: manifest("tasks.json", tasks), dataPrefix + "tasks.json": `[{"id":2,"title":"a"}]`}, "checksum"},
+		{"missing file", map[string]string{manifestName: manifest("tasks.json", tasks), dataPrefix + "other.json": tasks}, "missing"},
+		{"extra file", map[string]string{manifestName: manifest("tasks.json", tasks), dataPrefix + "tasks.json": tasks, dataPrefix + "x": ""}, "files"},
+		{"invalid tasks", map[string]string{manifestName: manifest("tasks.json", "{"), dataPrefix + "tasks.json": "{"}, "invalid task file"},
+		{"hidden file", map[string]string{manifestName: manifest(".x", ""), dataPrefix + ".x": ""}, "invalid file name"},
+		{"newer version", map[string]string{manifestName: `{"version":2}`}, "newer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(archiveWith(t, tt.entries))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Read: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want one with %q", err, tt.wantErr)
+			}
+		})
+	}
+}
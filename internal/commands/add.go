@@ -2,12 +2,24 @@ package commands
 
 import (
 	"flag"
-	"task-cli/internal/task"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"strings"
+	"time"
 )
 
 type AddCommand struct {
 	tm        task.ITaskManager
 	presenter Presenter
+
+	// Flags, defined by DefineFlags
+	title        *string
+	priorityFlag *string
+	dueDate      *string
+	reminder     *string
+	tags         *string
+	estimate     *string
+	dryRun       *bool
 }
 
 // NewAddCommand creates a new instance of AddCommand
@@ -18,54 +30,119 @@ func NewAddCommand(tm task.ITaskManager, p Presenter) *AddCommand {
 	}
 }
 
+// DefineFlags defines the flags of the add command
+func (c *AddCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.title = cmd.String("title", "", "Task title")
+	c.priorityFlag = cmd.String("priority", task.DefaultPriority.String(), "Task priority (none, someday, low, medium, high, urgent)")
+	c.dueDate = cmd.String("due", "", "Due date (format: YYYY-MM-DD HH:MM)")
+	c.reminder = cmd.String("reminder", "", "Reminder time (format: YYYY-MM-DD HH:MM)")
+	c.tags = cmd.String("tags", "", "Task tags (comma separated)")
+	c.estimate = cmd.String("estimate", "", "Estimated effort (e.g. 2h, 1h30m)")
+	c.dryRun = cmd.Bool("dry-run", false, "Show what would be added without adding it")
+}
+
 // Execute executes the add command
 func (c *AddCommand) Execute(args []string) error {
-	cmd := flag.NewFlagSet("add", flag.ExitOnError)
-	title := cmd.String("title", "", "Task title")
-	priorityFlag := cmd.String("priority", task.DefaultPriority.String(), "Task priority (low, medium, high)")
-	dueDate := cmd.String("due", "", "Due date (format: YYYY-MM-DD HH:MM)")
-	reminder := cmd.String("reminder", "", "Reminder time (format: YYYY-MM-DD HH:MM)")
+	cmd := newFlagSet("add")
+	c.DefineFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
 
-	if err := cmd.Parse(args); err != nil {
-		return c.presenter.PrintError("error parsing arguments: %v", err)
+	// The text after the flags is a task in the quick-add syntax, the flags
+	// given as well take precedence over it
+	var quick task.QuickAdd
+	text := strings.Join(cmd.Args(), " ")
+	if text != "" {
+		if *c.title != "" {
+			return c.presenter.PrintError("%w", newUsageError("give the title with -title or in the text, not both"))
+		}
+		var err error
+		if quick, err = task.ParseQuickAdd(text, time.Now()); err != nil {
+			return c.presenter.PrintError("invalid task: %w", err)
+		}
+		*c.title = quick.Title
 	}
+	set := make(map[string]bool)
+	cmd.Visit(func(f *flag.Flag) { set[f.Name] = true })
 
-	if *title == "" {
-		return c.presenter.PrintError("task title is required")
+	if *c.title == "" {
+		return c.presenter.PrintError("%w", task.ErrTitleRequired)
 	}
 
-	priority, err := task.ParsePriority(*priorityFlag)
+	priority, err := task.ParsePriority(*c.priorityFlag)
 	if err != nil {
-		return c.presenter.PrintError("invalid priority: %v", err)
+		return c.presenter.PrintError("invalid priority: %w", err)
+	}
+	if quick.HasPriority && !set["priority"] && !set["p"] {
+		priority = quick.Priority
 	}
 
-	// Create the task
-	newTask := c.tm.AddTask(*title, priority)
+	taskTags := quick.Tags
+	if *c.tags != "" {
+		taskTags = task.NormalizeTags(append(taskTags, strings.Split(*c.tags, ",")...))
+	}
 
-	// Set due date if provided
-	if *dueDate != "" {
-		due, err := task.ParseDateTime(*dueDate)
-		if err != nil {
-			return c.presenter.PrintError("invalid due date: %v", err)
+	var effort time.Duration
+	if *c.estimate != "" {
+		if effort, err = task.ParseDuration(*c.estimate); err != nil {
+			return c.presenter.PrintError("invalid estimate: %w", err)
 		}
-		if err := c.tm.SetDueDate(newTask.ID, due); err != nil {
-			return c.presenter.PrintError("error setting due date: %v", err)
+	}
+
+	due := quick.DueDate
+	if *c.dueDate != "" {
+		d, err := task.ParseDateTime(*c.dueDate)
+		if err != nil {
+			return c.presenter.PrintError("invalid due date: %w", err)
 		}
+		due = &d
 	}
 
-	// Set reminder if provided
-	if *reminder != "" {
-		rem, err := task.ParseDateTime(*reminder)
+	rem := quick.Reminder
+	if *c.reminder != "" {
+		r, err := task.ParseDateTime(*c.reminder)
 		if err != nil {
-			return c.presenter.PrintError("invalid reminder time: %v", err)
+			return c.presenter.PrintError("invalid reminder time: %w", err)
 		}
-		if err := c.tm.SetReminder(newTask.ID, rem); err != nil {
-			return c.presenter.PrintError("error setting reminder: %v", err)
+		rem = &r
+	}
+	if err := task.ValidateTimeOrder(due, rem); err != nil {
+		return c.presenter.PrintError("invalid reminder time: %w", err)
+	}
+
+	if *c.dryRun {
+		c.presenter.PrintSuccess("Would add:\n%s", addPreview(*c.title, priority, taskTags, effort, due, rem))
+		return nil
+	}
+
+	// Create the task
+	newTask := c.tm.AddTask(*c.title, priority)
+
+	if len(taskTags) > 0 {
+		if err := c.tm.SetTags(newTask.ID, taskTags); err != nil {
+			return c.presenter.PrintError("error setting tags: %w", err)
+		}
+	}
+	if effort > 0 {
+		if err := c.tm.SetEstimate(newTask.ID, effort); err != nil {
+			return c.presenter.PrintError("error setting estimate: %w", err)
+		}
+	}
+	if due != nil {
+		if err := c.tm.SetDueDate(newTask.ID, *due); err != nil {
+			return c.presenter.PrintError("error setting due date: %w", err)
+		}
+	}
+	if rem != nil {
+		if err := c.tm.SetReminder(newTask.ID, *rem); err != nil {
+			return c.presenter.PrintError("error setting reminder: %w", err)
 		}
 	}
 
 	if err := c.tm.SaveTasks(); err != nil {
-		return c.presenter.PrintError("error saving task: %v", err)
+		return c.presenter.PrintError("error saving task: %w", err)
 	}
 
 	c.presenter.PrintSuccess("Task added with ID: %d (Priority: %s%s%s)",
@@ -74,19 +151,68 @@ func (c *AddCommand) Execute(args []string) error {
 		newTask.Priority.String(),
 		"\033[0m")
 
+	// Show what was understood of the quick-add text
+	if text != "" {
+		c.presenter.PrintSuccess("%s", addPreview(*c.title, newTask.Priority, taskTags, effort, due, rem))
+	}
+
 	return nil
 }
 
+// addPreview describes the task that is added
+func addPreview(title string, priority task.TaskPriority, tags []string, estimate time.Duration, due, reminder *time.Time) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "  Title:     %s\n", title)
+	fmt.Fprintf(&sb, "  Priority:  %s%s\033[0m\n", priority.Color(), priority.String())
+	if len(tags) > 0 {
+		fmt.Fprintf(&sb, "  Tags:      #%s\n", strings.Join(tags, " #"))
+	}
+	if estimate > 0 {
+		fmt.Fprintf(&sb, "  Estimate:  %s\n", task.FormatDuration(estimate))
+	}
+	if due != nil {
+		fmt.Fprintf(&sb, "  Due:       %s\n", task.FormatDateTime(due))
+	}
+	if reminder != nil {
+		fmt.Fprintf(&sb, "  Reminder:  %s\n", task.FormatDateTime(reminder))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
 // Help returns information about the add command
 func (c *AddCommand) Help() string {
 	return `Create a new task
     
 Usage:
   task add [flags]
+  task add [flags] <text>
 
 Flags:
   -title string      Task title (required)
-  -priority string   Task priority: low, medium, high (default: medium)
+  -priority string   Task priority: none, someday, low, medium, high,
+                     urgent (default: medium)
   -due string        Due date (format: YYYY-MM-DD HH:MM)
-  -reminder string   Reminder time (format: YYYY-MM-DD HH:MM)`
+  -reminder string   Reminder time (format: YYYY-MM-DD HH:MM)
+  -tags string       Task tags (comma separated)
+  -estimate string   Estimated effort (e.g. 2h, 1h30m)
+  -dry-run           Show what would be added without adding it
+
+Quick add:
+  The task can be written as text, the words below are taken out of it and
+  the rest is the title. Flags given as well take precedence.
+
+  !<priority>            Priority, like !high or !someday, other !words stay
+                         in the title
+  #tag                   Tag, can be repeated
+  due:<date> [time]      Due date
+  remind:<date> [time]   Reminder, or remind:-2h for 2 hours before the due date
+
+  Dates: YYYY-MM-DD, today, tomorrow, a weekday (mon, friday...) or +3d.
+  Times: 17:00 or 5pm, the end of the day when not given. Like -due, they
+  are kept as written, with today and +3d counted from the local time.
+  Start a word with a backslash to keep it in the title, like \#1.
+
+Examples:
+  task add 'Fix login bug !high due:fri 17:00 #backend remind:-2h'
+  task add -dry-run 'Call the bank due:tomorrow 9am'`
 }
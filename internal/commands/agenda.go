@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"sort"
+	"strings"
+	"time"
+)
+
+// agendaEntry is a due date or reminder shown in the agenda
+type agendaEntry struct {
+	at   time.Time
+	line string
+}
+
+type AgendaCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+
+	// Flags, defined by DefineFlags
+	days *int
+}
+
+// NewAgendaCommand creates a new instance of AgendaCommand
+func NewAgendaCommand(tm task.ITaskManager, p Presenter) *AgendaCommand {
+	return &AgendaCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// DefineFlags defines the flags of the agenda command
+func (c *AgendaCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.days = cmd.Int("days", 7, "Number of days to show")
+}
+
+// Execute executes the agenda command
+func (c *AgendaCommand) Execute(args []string) error {
+	cmd := newFlagSet("agenda")
+	c.DefineFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	if *c.days < 1 {
+		return c.presenter.PrintError("%w", newUsageError("days must be at least 1"))
+	}
+
+	c.presenter.PrintSuccess(formatAgenda(c.tm.GetTasksSorted(false, true), time.Now(), *c.days))
+	return nil
+}
+
+// formatAgenda renders the overdue tasks and the due dates and reminders
+// of the next days, grouped by day
+func formatAgenda(tasks []task.Task, now time.Time, days int) string {
+	var sb strings.Builder
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	end := today.AddDate(0, 0, days)
+
+	var overdue []task.Task
+	byDay := make(map[string][]agendaEntry)
+	for _, t := range tasks {
+		if t.Done {
+			continue
+		}
+		if t.DueDate != nil && t.DueDate.Before(today) {
+			overdue = append(overdue, t)
+		}
+		if t.DueDate != nil && !t.DueDate.Before(today) && t.DueDate.Before(end) {
+			key := t.DueDate.Format("2006-01-02")
+			byDay[key] = append(byDay[key], agendaEntry{*t.DueDate, formatAgendaTask(t, t.DueDate, "15:04")})
+		}
+		if t.Reminder != nil && !t.Reminder.Before(today) && t.Reminder.Before(end) {
+			key := t.Reminder.Format("2006-01-02")
+			byDay[key] = append(byDay[key], agendaEntry{*t.Reminder, formatAgendaTask(t, t.Reminder, "15:04")})
+		}
+	}
+
+	if len(overdue) > 0 {
+		sb.WriteString(task.TimeStatusOverdue.Color() + "Overdue" + "\033[0m\n")
+		for _, t := range overdue {
+			sb.WriteString(formatAgendaTask(t, t.DueDate, "2006-01-02 15:04"))
+		}
+		sb.WriteString("\n")
+	}
+
+	for day := today; day.Before(end); day = day.AddDate(0, 0, 1) {
+		header := day.Format("Mon 02 Jan 2006")
+		switch {
+		case day.Equal(today):
+			header += " (today)"
+		case day.Equal(today.AddDate(0, 0, 1)):
+			header += " (tomorrow)"
+		}
+		sb.WriteString(header + "\n")
+
+		entries := byDay[day.Format("2006-01-02")]
+		if len(entries) == 0 {
+			sb.WriteString("   ---\n")
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].at.Before(entries[j].at)
+		})
+		for _, entry := range entries {
+			sb.WriteString(entry.line)
+		}
+		sb.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n\n")
+}
+
+// formatAgendaTask formats a line of the agenda for a task due date or
+// reminder at the given time
+func formatAgendaTask(t task.Task, at *time.Time, layout string) string {
+	kind := "due"
+	if at == t.Reminder {
+		kind = "remind"
+	}
+
+	status := t.GetTimeStatus()
+	return fmt.Sprintf("   %s %s%-6s\033[0m #%d %s%s%s (%s)\n",
+		at.Format(layout),
+		status.Color(),
+		kind,
+		t.ID,
+		t.Priority.Color(),
+		t.Title,
+		"\033[0m",
+		t.Priority.String())
+}
+
+// Help returns the help message for the agenda command
+func (c *AgendaCommand) Help() string {
+	return `List the due dates and reminders of the next days
+
+Usage:
+  task agenda [flags]
+
+Flags:
+  -days int   Number of days to show, starting today (default: 7)
+
+Overdue tasks are listed first.`
+}
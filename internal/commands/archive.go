@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"flag"
+	"github.com/kubaliski/task-cli/internal/task"
+	"time"
+)
+
+type ArchiveCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+
+	// Flags, defined by DefineFlags
+	olderThan *string
+}
+
+// NewArchiveCommand creates a new instance of ArchiveCommand
+func NewArchiveCommand(tm task.ITaskManager, p Presenter) *ArchiveCommand {
+	return &ArchiveCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// DefineFlags defines the flags of the archive command
+func (c *ArchiveCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.olderThan = cmd.String("older-than", "", "Only archive tasks completed before this age (e.g. 30d, 12h)")
+}
+
+// Execute executes the archive command
+func (c *ArchiveCommand) Execute(args []string) error {
+	cmd := newFlagSet("archive")
+	c.DefineFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	var ids []int
+	for _, arg := range cmd.Args() {
+		id, err := parseID(arg)
+		if err != nil {
+			return c.presenter.PrintError("%w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	var age time.Duration
+	if *c.olderThan != "" {
+		if len(ids) > 0 {
+			return c.presenter.PrintError("%w", newUsageError("-older-than can't be combined with task IDs"))
+		}
+		d, err := parseAge(*c.olderThan)
+		if err != nil {
+			return c.presenter.PrintError("invalid age: %w", err)
+		}
+		age = d
+	}
+
+	archived, err := c.tm.ArchiveTasks(ids, age)
+	if err != nil {
+		return c.presenter.PrintError("error archiving tasks: %w", err)
+	}
+
+	if len(archived) == 0 {
+		c.presenter.PrintSuccess("No completed tasks to archive")
+		return nil
+	}
+
+	if err := c.tm.SaveTasks(); err != nil {
+		return c.presenter.PrintError("error saving changes: %w", err)
+	}
+
+	c.presenter.PrintSuccess("%d task(s) archived", len(archived))
+	return nil
+}
+
+// Help returns the help message for the archive command
+func (c *ArchiveCommand) Help() string {
+	return `Move completed tasks to the archive
+
+Usage:
+  task archive [flags] [id...]
+
+Arguments:
+  [id...]    Optional IDs of completed tasks to archive. Without IDs
+             every completed task is archived
+
+Flags:
+  -older-than string   Only archive tasks completed before this age,
+                       greater than zero, e.g. 30d, 2w, 12h
+
+Archived tasks can be listed with 'task list -archived'
+and brought back with 'task unarchive <id>'.`
+}
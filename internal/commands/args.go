@@ -0,0 +1,199 @@
+package commands
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Values of the --color global flag
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// Values of the --format global flag
+const (
+	FormatTable = "table"
+	FormatList  = "list"
+	FormatJSON  = "json"
+)
+
+// Options are the global flags, shared by all the commands
+type Options struct {
+	// DataDir replaces the default data directory (--db)
+	DataDir string
+	// Color tells when the output is colored: auto, always or never (--color)
+	Color string
+	// Format replaces the way the commands show tasks: table, list or json
+	// (--format)
+	Format string
+	// JSONErrors prints the errors as JSON (--json)
+	JSONErrors bool
+}
+
+// commandAliases are other names of the commands
+var commandAliases = map[string]string{
+	"ls": "list",
+	"rm": "delete",
+}
+
+// shortFlags are the short aliases of the command flags, added to the
+// commands that have the long flag
+var shortFlags = map[string]string{
+	"a": "all",
+	"d": "due",
+	"e": "estimate",
+	"f": "format",
+	"n": "note",
+	"p": "priority",
+	"r": "reminder",
+	"t": "title",
+	"w": "where",
+	"y": "yes",
+}
+
+// ParseOptions takes the global flags out of the command line arguments.
+// --db, --color and --json are accepted anywhere, --format only before the
+// command name since several commands have their own -format flag.
+func ParseOptions(args []string) (Options, []string, error) {
+	opts := Options{Color: ColorAuto}
+	var rest []string
+	seenCommand := false
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		name, value, hasValue := splitFlag(arg)
+		global := name == "db" || name == "color" || name == "json" || (name == "format" && !seenCommand)
+		if !global {
+			if !seenCommand && !strings.HasPrefix(arg, "-") {
+				seenCommand = true
+			}
+			rest = append(rest, arg)
+			continue
+		}
+
+		if name == "json" {
+			opts.JSONErrors = true
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return opts, nil, newUsageError("flag needs an argument: --%s", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch name {
+		case "db":
+			opts.DataDir = value
+		case "color":
+			if value != ColorAuto && value != ColorAlways && value != ColorNever {
+				return opts, nil, newUsageError("invalid --color: %s (use auto, always or never)", value)
+			}
+			opts.Color = value
+		case "format":
+			if value != FormatTable && value != FormatList && value != FormatJSON {
+				return opts, nil, newUsageError("invalid --format: %s (use table, list or json)", value)
+			}
+			opts.Format = value
+		}
+	}
+	return opts, rest, nil
+}
+
+// splitFlag returns the name and the value of a flag argument like -name,
+// --name or --name=value. The name is empty when the argument isn't a flag.
+func splitFlag(arg string) (name, value string, hasValue bool) {
+	if len(arg) < 2 || arg[0] != '-' {
+		return "", "", false
+	}
+	name = strings.TrimPrefix(arg[1:], "-")
+	name, value, hasValue = strings.Cut(name, "=")
+	return name, value, hasValue
+}
+
+// resolveAlias returns the name of the command of an alias
+func resolveAlias(name string) string {
+	if target, ok := commandAliases[name]; ok {
+		return target
+	}
+	return name
+}
+
+// newFlagSet creates the flag set of a command. Parse errors are returned
+// instead of exiting, and nothing is printed.
+func newFlagSet(name string) *flag.FlagSet {
+	cmd := flag.NewFlagSet(name, flag.ContinueOnError)
+	cmd.SetOutput(io.Discard)
+	return cmd
+}
+
+// parseArgs parses the flags of a command wherever they are among its
+// positional arguments, so "task update 3 -done" and "task update -done 3"
+// are the same. The positional arguments are left in cmd.Args(), in order,
+// and everything after "--" is positional. The short aliases of the flags,
+// like -p for -priority, are added before parsing.
+func parseArgs(cmd *flag.FlagSet, args []string) error {
+	for short, long := range shortFlags {
+		if f := cmd.Lookup(long); f != nil && cmd.Lookup(short) == nil {
+			cmd.Var(f.Value, short, "Alias of -"+long)
+		}
+	}
+
+	var positional []string
+	for {
+		if err := cmd.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return err
+			}
+			return newUsageError("%v", err)
+		}
+
+		rest := cmd.Args()
+		if len(rest) == 0 {
+			break
+		}
+		// The flag package stops at "--" and at the first positional argument
+		if len(rest) < len(args) && args[len(args)-len(rest)-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+
+	// Parse the positional arguments alone so cmd.Args() returns them
+	return cmd.Parse(append([]string{"--"}, positional...))
+}
+
+// parseID parses a task ID given as an argument
+func parseID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", task.ErrInvalidID, s)
+	}
+	return id, nil
+}
+
+// parseAge parses the age given to -older-than, which must be greater than
+// zero: an age of zero would match every task
+func parseAge(s string) (time.Duration, error) {
+	d, err := task.ParseDuration(s)
+	if err == nil && d == 0 {
+		err = fmt.Errorf("%w: %s is not greater than zero", task.ErrInvalidDuration, s)
+	}
+	return d, err
+}
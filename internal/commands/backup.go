@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"github.com/kubaliski/task-cli/internal/backup"
+	"github.com/kubaliski/task-cli/internal/task"
+	"os"
+)
+
+type BackupCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+}
+
+// NewBackupCommand creates a new instance of BackupCommand
+func NewBackupCommand(tm task.ITaskManager, p Presenter) *BackupCommand {
+	return &BackupCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// Execute executes the backup command
+func (c *BackupCommand) Execute(args []string) error {
+	cmd := newFlagSet("backup")
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	if len(cmd.Args()) == 0 {
+		return c.presenter.PrintError("%w", newUsageError("backup file is required"))
+	}
+	fileName := cmd.Args()[0]
+
+	dataDir, err := task.DataDir()
+	if err != nil {
+		return c.presenter.PrintError("error finding data directory: %w", err)
+	}
+
+	f, err := os.Create(fileName)
+	if err != nil {
+		return c.presenter.PrintError("error creating backup file: %w", err)
+	}
+
+	manifest, err := backup.Create(dataDir, f)
+	if err != nil {
+		f.Close()
+		os.Remove(fileName)
+		return c.presenter.PrintError("error creating backup: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return c.presenter.PrintError("error writing backup file: %w", err)
+	}
+
+	c.presenter.PrintSuccess("Backup of %d file(s) written to %s", len(manifest.Files), fileName)
+	return nil
+}
+
+// Help returns the help message for the backup command
+func (c *BackupCommand) Help() string {
+	return `Back up all the task data to a single file
+
+Usage:
+  task backup <file>
+
+Arguments:
+  <file>    Backup file to create (a .tar.gz archive)
+
+The backup includes every data file with a manifest of their
+checksums. Restore it with 'task restore <file>'.`
+}
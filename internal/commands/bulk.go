@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"flag"
+	"github.com/kubaliski/task-cli/internal/task"
+	"strings"
+)
+
+// bulkFlags holds the flags shared by the commands that can act on
+// several tasks at once
+type bulkFlags struct {
+	where  *string
+	dryRun *bool
+	yes    *bool
+}
+
+// addBulkFlags registers the bulk flags on a flag set
+func addBulkFlags(cmd *flag.FlagSet) *bulkFlags {
+	return &bulkFlags{
+		where:  cmd.String("where", "", "Select tasks with a filter expression instead of IDs"),
+		dryRun: cmd.Bool("dry-run", false, "Show the affected tasks without changing anything"),
+		yes:    cmd.Bool("yes", false, "Don't ask for confirmation"),
+	}
+}
+
+// resolveTargets returns the tasks selected either by ID lists and ranges
+// or by a filter expression
+func resolveTargets(tm task.ITaskManager, p Presenter, idArgs []string, where string) ([]task.Task, error) {
+	if where != "" {
+		if len(idArgs) > 0 {
+			return nil, p.PrintError("%w", newUsageError("task IDs and -where can't be combined"))
+		}
+		f, err := task.ParseFilter(where)
+		if err != nil {
+			return nil, p.PrintError("invalid filter: %w", err)
+		}
+		targets := f.Apply(tm.GetTasksSorted(false, false))
+		if len(targets) == 0 {
+			return nil, p.PrintError("no tasks match the filter: %s", where)
+		}
+		return targets, nil
+	}
+
+	if len(idArgs) == 0 {
+		return nil, p.PrintError("%w", newUsageError("task ID is required"))
+	}
+
+	ids, err := task.ParseIDs(strings.Join(idArgs, ","))
+	if err != nil {
+		return nil, p.PrintError("invalid task ID: %w", err)
+	}
+
+	// Every task must exist before anything is changed
+	targets := make([]task.Task, 0, len(ids))
+	for _, id := range ids {
+		t, err := tm.GetTaskByID(id)
+		if err != nil {
+			return nil, p.PrintError("task not found: %w", err)
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// confirmBulk shows the tasks affected by a bulk operation and asks the user
+// to confirm it. Operations on a single task selected by ID are not confirmed.
+// It returns false if the operation must not be applied.
+func confirmBulk(p Presenter, action string, targets []task.Task, flags *bulkFlags) (bool, error) {
+	if len(targets) == 1 && *flags.where == "" && !*flags.dryRun {
+		return true, nil
+	}
+
+	if err := p.PrintTaskTable(targets); err != nil {
+		return false, err
+	}
+
+	if *flags.dryRun {
+		p.PrintSuccess("Dry run: %d task(s) would be %s", len(targets), action)
+		return false, nil
+	}
+
+	if *flags.yes {
+		return true, nil
+	}
+
+	if !p.Confirm("%d task(s) will be %s. Continue?", len(targets), action) {
+		p.PrintSuccess("Aborted, no tasks were %s", action)
+		return false, nil
+	}
+	return true, nil
+}
+
+// statusChange is a bulk change of the completion status of tasks, shared
+// by the done and reopen commands
+type statusChange struct {
+	name     string // Name of the command
+	noteHelp string // Description of the -note flag
+	done     bool   // Completion status the tasks end with
+	action   string // What happens to the tasks, e.g. "completed"
+	skipped  string // Why a task is skipped, e.g. "is already completed"
+	failure  string // Error prefix, e.g. "error completing task"
+	apply    func(id int, note string) error
+}
+
+// statusFlags are the flags of the done and reopen commands
+type statusFlags struct {
+	note *string
+	bulk *bulkFlags
+}
+
+// addStatusFlags registers the flags of a status change on a flag set
+func addStatusFlags(cmd *flag.FlagSet, change statusChange) *statusFlags {
+	return &statusFlags{
+		note: cmd.String("note", "", change.noteHelp),
+		bulk: addBulkFlags(cmd),
+	}
+}
+
+// runStatusChange parses the arguments of a done or reopen command and
+// changes the completion status of the selected tasks
+func runStatusChange(tm task.ITaskManager, p Presenter, args []string, change statusChange) error {
+	cmd := newFlagSet(change.name)
+	flags := addStatusFlags(cmd, change)
+	if err := parseArgs(cmd, args); err != nil {
+		return p.PrintError("error parsing arguments: %w", err)
+	}
+
+	targets, err := resolveTargets(tm, p, cmd.Args(), *flags.bulk.where)
+	if err != nil {
+		return err
+	}
+
+	// Tasks that already have the status are left untouched
+	var changed []task.Task
+	for _, t := range targets {
+		if t.Done == change.done {
+			p.PrintSuccess("Task %d %s, skipping", t.ID, change.skipped)
+			continue
+		}
+		changed = append(changed, t)
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	ok, err := confirmBulk(p, change.action, changed, flags.bulk)
+	if err != nil || !ok {
+		return err
+	}
+
+	for _, t := range changed {
+		if err := change.apply(t.ID, *flags.note); err != nil {
+			return p.PrintError("%s: %w", change.failure, err)
+		}
+	}
+
+	if err := tm.SaveTasks(); err != nil {
+		return p.PrintError("error saving changes: %w", err)
+	}
+
+	if len(changed) == 1 {
+		p.PrintSuccess("Task %d %s", changed[0].ID, change.action)
+	} else {
+		p.PrintSuccess("%d tasks %s", len(changed), change.action)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"strings"
+	"time"
+)
+
+// calendarCellWidth is the visible width of a day in the month grid
+const calendarCellWidth = 8
+
+type CalendarCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+
+	// Flags, defined by DefineFlags
+	showCompleted *bool
+}
+
+// NewCalendarCommand creates a new instance of CalendarCommand
+func NewCalendarCommand(tm task.ITaskManager, p Presenter) *CalendarCommand {
+	return &CalendarCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// DefineFlags defines the flags of the calendar command
+func (c *CalendarCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.showCompleted = cmd.Bool("all", false, "Include completed tasks")
+}
+
+// Execute executes the calendar command
+func (c *CalendarCommand) Execute(args []string) error {
+	cmd := newFlagSet("calendar")
+	c.DefineFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	month := time.Now()
+	if len(cmd.Args()) > 0 {
+		m, err := parseMonth(cmd.Args()[0], month)
+		if err != nil {
+			return c.presenter.PrintError("invalid month: %w", err)
+		}
+		month = m
+	}
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+
+	// Group the due tasks of the month by day
+	byDay := make(map[int][]task.Task)
+	for _, t := range c.tm.GetTasksSorted(false, true) {
+		if t.DueDate == nil || (t.Done && !*c.showCompleted) {
+			continue
+		}
+		if t.DueDate.Year() == first.Year() && t.DueDate.Month() == first.Month() {
+			byDay[t.DueDate.Day()] = append(byDay[t.DueDate.Day()], t)
+		}
+	}
+
+	c.presenter.PrintSuccess(formatCalendar(first, byDay))
+	return nil
+}
+
+// parseMonth parses a month as YYYY-MM, a month number or a month name
+// of the current year
+func parseMonth(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, fmt.Errorf("empty month")
+	}
+
+	if t, err := time.Parse("2006-01", s); err == nil {
+		return t, nil
+	}
+
+	// Month names are parsed capitalized, like "October"
+	name := strings.ToUpper(s[:1]) + strings.ToLower(s[1:])
+	for _, layout := range []string{"1", "January", "Jan"} {
+		if t, err := time.Parse(layout, name); err == nil {
+			return time.Date(now.Year(), t.Month(), 1, 0, 0, 0, 0, now.Location()), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("expected YYYY-MM, a month number or a month name: %s", s)
+}
+
+// formatCalendar renders a month grid with the number of tasks due each day,
+// colored by their most urgent time status, followed by the tasks themselves
+func formatCalendar(first time.Time, byDay map[int][]task.Task) string {
+	var sb strings.Builder
+
+	title := first.Format("January 2006")
+	gridWidth := 7 * calendarCellWidth
+	sb.WriteString(strings.Repeat(" ", (gridWidth-len(title))/2) + title + "\n")
+
+	for _, name := range []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"} {
+		sb.WriteString(centerText(name, calendarCellWidth))
+	}
+	sb.WriteString("\n")
+
+	today := time.Now()
+	daysInMonth := first.AddDate(0, 1, -1).Day()
+	offset := (int(first.Weekday()) + 6) % 7
+	sb.WriteString(strings.Repeat(" ", offset*calendarCellWidth))
+
+	for day := 1; day <= daysInMonth; day++ {
+		cell := fmt.Sprintf("%2d", day)
+		if tasks := byDay[day]; len(tasks) > 0 {
+			cell += fmt.Sprintf(" •%d", len(tasks))
+			cell = worstStatus(tasks).Color() + cell + "\033[0m"
+		}
+		if today.Year() == first.Year() && today.Month() == first.Month() && today.Day() == day {
+			cell = "[" + cell + "]"
+		}
+		sb.WriteString(centerText(cell, calendarCellWidth))
+
+		if (offset+day)%7 == 0 {
+			sb.WriteString("\n")
+		}
+	}
+	sb.WriteString("\n")
+
+	for day := 1; day <= daysInMonth; day++ {
+		tasks := byDay[day]
+		if len(tasks) == 0 {
+			continue
+		}
+		date := time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, first.Location())
+		fmt.Fprintf(&sb, "\n%s\n", date.Format("Mon 02 Jan"))
+		for _, t := range tasks {
+			sb.WriteString(formatAgendaTask(t, t.DueDate, "15:04"))
+		}
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// worstStatus returns the most urgent time status of the tasks
+func worstStatus(tasks []task.Task) task.TimeStatus {
+	worst := task.TimeStatusNormal
+	for _, t := range tasks {
+		if status := t.GetTimeStatus(); status > worst {
+			worst = status
+		}
+	}
+	return worst
+}
+
+// Help returns the help message for the calendar command
+func (c *CalendarCommand) Help() string {
+	return `Show a month calendar with the tasks due each day
+
+Usage:
+  task calendar [month] [flags]
+
+Arguments:
+  [month]    Month to show: YYYY-MM, a month number or name
+             (default: current month)
+
+Flags:
+  -all       Include completed tasks
+
+Days are colored by the most urgent status of their tasks.`
+}
@@ -2,7 +2,9 @@
 package commands
 
 import (
-	"task-cli/internal/task"
+	"errors"
+	"flag"
+	"github.com/kubaliski/task-cli/internal/task"
 )
 
 // Commander cordinates all the commands
@@ -12,11 +14,12 @@ type Commander struct {
 	commands  map[string]Command
 }
 
-// NewCommander create a new instance of Commander
-func NewCommander(tm task.ITaskManager) *Commander {
+// NewCommander create a new instance of Commander, its output follows the
+// global options
+func NewCommander(tm task.ITaskManager, opts Options) *Commander {
 	c := &Commander{
 		tm:        tm,
-		presenter: NewDefaultPresenter(),
+		presenter: NewPresenter(opts),
 		commands:  make(map[string]Command),
 	}
 	c.registerCommands()
@@ -26,23 +29,62 @@ func NewCommander(tm task.ITaskManager) *Commander {
 // registerCommands registers all the commands
 func (c *Commander) registerCommands() {
 	c.commands = map[string]Command{
-		"add":    NewAddCommand(c.tm, c.presenter),
-		"list":   NewListCommand(c.tm, c.presenter),
-		"update": NewUpdateCommand(c.tm, c.presenter),
-		"delete": NewDeleteCommand(c.tm, c.presenter),
-		"get":    NewGetCommand(c.tm, c.presenter),
+		"add":       NewAddCommand(c.tm, c.presenter),
+		"list":      NewListCommand(c.tm, c.presenter),
+		"next":      NewNextCommand(c.tm, c.presenter),
+		"update":    NewUpdateCommand(c.tm, c.presenter),
+		"delete":    NewDeleteCommand(c.tm, c.presenter),
+		"done":      NewDoneCommand(c.tm, c.presenter),
+		"reopen":    NewReopenCommand(c.tm, c.presenter),
+		"start":     NewStartCommand(c.tm, c.presenter),
+		"stop":      NewStopCommand(c.tm, c.presenter),
+		"log":       NewLogCommand(c.tm, c.presenter),
+		"stats":     NewStatsCommand(c.tm, c.presenter),
+		"report":    NewReportCommand(c.tm, c.presenter),
+		"calendar":  NewCalendarCommand(c.tm, c.presenter),
+		"agenda":    NewAgendaCommand(c.tm, c.presenter),
+		"export":    NewExportCommand(c.tm, c.presenter),
+		"import":    NewImportCommand(c.tm, c.presenter),
+		"backup":    NewBackupCommand(c.tm, c.presenter),
+		"sync":      NewSyncCommand(c.tm, c.presenter),
+		"merge":     NewMergeCommand(c.tm, c.presenter),
+		"serve":     NewServeCommand(c.tm, c.presenter),
+		"daemon":    NewDaemonCommand(c.tm, c.presenter),
+		"hooks":     NewHooksCommand(c.tm, c.presenter),
+		"get":       NewGetCommand(c.tm, c.presenter),
+		"trash":     NewTrashCommand(c.tm, c.presenter),
+		"restore":   NewRestoreCommand(c.tm, c.presenter),
+		"archive":   NewArchiveCommand(c.tm, c.presenter),
+		"unarchive": NewUnarchiveCommand(c.tm, c.presenter),
 	}
 	// Help command needs the list of commands
 	c.commands["help"] = NewHelpCommand(c.commands, c.presenter)
+	c.commands["completion"] = NewCompletionCommand(c.commands, c.tm, c.presenter)
 }
 
-// Execute executes the command with the given name
+// Execute executes the command with the given name or alias
 func (c *Commander) Execute(cmdName string, args []string) error {
+	cmdName = resolveAlias(cmdName)
 	cmd, exists := c.commands[cmdName]
 	if !exists {
-		return c.presenter.PrintError("unknown command: %s", cmdName)
+		return c.presenter.PrintError("%w: %s", ErrUnknownCommand, cmdName)
 	}
-	return cmd.Execute(args)
+	if err := cmd.Execute(args); err != nil {
+		// -h and -help show the help of the command
+		if errors.Is(err, flag.ErrHelp) {
+			c.presenter.PrintSuccess(cmd.Help())
+			return nil
+		}
+		return err
+	}
+
+	// Changes are committed when the data directory is synced with git
+	if cmdName != "sync" {
+		if err := commitChanges(cmdName, args); err != nil {
+			return c.presenter.PrintError("error recording changes for sync: %w", err)
+		}
+	}
+	return nil
 }
 
 // SetPresenter allows to set a custom presenter
@@ -0,0 +1,502 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Kinds of task IDs completed for the arguments of the commands
+const (
+	idsAll     = "all"
+	idsPending = "pending"
+	idsDone    = "done"
+	idsTrash   = "trash"
+	idsArchive = "archive"
+)
+
+// completionIDs tells which task IDs complete the arguments of a command
+var completionIDs = map[string]string{
+	"get":       idsAll,
+	"update":    idsAll,
+	"delete":    idsAll,
+	"log":       idsAll,
+	"done":      idsPending,
+	"start":     idsPending,
+	"reopen":    idsDone,
+	"archive":   idsDone,
+	"unarchive": idsArchive,
+	"restore":   idsTrash,
+}
+
+// completionWords are the subcommands of the commands that have them
+var completionWords = map[string][]string{
+	"trash":      {"list", "empty"},
+	"sync":       {"init"},
+	"hooks":      {"list", "log", "test"},
+	"completion": {"bash", "zsh", "fish"},
+}
+
+// completionFiles are the commands that take files as arguments, and the
+// flags, as "command -flag", whose value is a file
+var completionFiles = map[string]bool{
+	"import":      true,
+	"backup":      true,
+	"restore":     true,
+	"merge":       true,
+	"export -o":   true,
+	"merge -base": true,
+}
+
+// completionFormats are the values of the -format flag of every command
+var completionFormats = map[string][]string{
+	"list":   {"table", "list"},
+	"trash":  {"table", "list"},
+	"export": {"ics", "todotxt", "markdown"},
+	"import": {"ics", "taskwarrior", "todotxt", "csv"},
+	"stats":  {"text", "json"},
+	"report": {"text", "json"},
+}
+
+// completionSpec is what the shells complete for a command
+type completionSpec struct {
+	name        string
+	description string
+	flags       []completionFlag
+	words       []string
+	ids         string
+	files       bool
+}
+
+// completionFlag is a flag of a command
+type completionFlag struct {
+	name        string
+	description string
+	takesValue  bool
+	values      []string
+	files       bool
+}
+
+type CompletionCommand struct {
+	commands  map[string]Command
+	tm        task.ITaskManager
+	presenter Presenter
+}
+
+// NewCompletionCommand creates a new instance of CompletionCommand
+func NewCompletionCommand(commands map[string]Command, tm task.ITaskManager, p Presenter) *CompletionCommand {
+	return &CompletionCommand{
+		commands:  commands,
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// Execute executes the completion command
+func (c *CompletionCommand) Execute(args []string) error {
+	if len(args) == 0 {
+		return c.presenter.PrintError("%w", newUsageError("shell is required: bash, zsh or fish"))
+	}
+
+	var script string
+	switch args[0] {
+	case "bash":
+		script = bashCompletion(c.specs())
+	case "zsh":
+		script = zshCompletion(c.specs())
+	case "fish":
+		script = fishCompletion(c.specs())
+	case "ids":
+		return c.printIDs(args[1:])
+	default:
+		return c.presenter.PrintError("%w", newUsageError("unknown shell: %s", args[0]))
+	}
+
+	fmt.Fprint(os.Stdout, script)
+	return nil
+}
+
+// printIDs prints the IDs of the tasks of a kind with their titles, one
+// task per line separated by a tab, for the completion scripts
+func (c *CompletionCommand) printIDs(args []string) error {
+	kind := idsAll
+	if len(args) > 0 {
+		kind = args[0]
+	}
+
+	var tasks []task.Task
+	switch kind {
+	case idsAll, idsPending, idsDone:
+		for _, t := range c.tm.GetTasksSorted(false, false) {
+			if kind == idsAll || t.Done == (kind == idsDone) {
+				tasks = append(tasks, t)
+			}
+		}
+	case idsTrash:
+		tasks = c.tm.GetTrashedTasks()
+	case idsArchive:
+		tasks = c.tm.GetArchivedTasks()
+	default:
+		return c.presenter.PrintError("%w", newUsageError("unknown kind of IDs: %s", kind))
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	clean := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+	for _, t := range tasks {
+		fmt.Printf("%d\t%s\n", t.ID, clean.Replace(t.Title))
+	}
+	return nil
+}
+
+// specs describes the commands for the completion scripts, sorted by name
+func (c *CompletionCommand) specs() []completionSpec {
+	names := make([]string, 0, len(c.commands))
+	for name := range c.commands {
+		names = append(names, name)
+	}
+	// The aliases complete like their command
+	for alias := range commandAliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+
+	specs := make([]completionSpec, 0, len(names))
+	for _, name := range names {
+		cmdName := resolveAlias(name)
+		command := c.commands[cmdName]
+
+		spec := completionSpec{
+			name:        name,
+			description: strings.SplitN(command.Help(), "\n", 2)[0],
+			flags:       commandFlags(command),
+			words:       completionWords[cmdName],
+			ids:         completionIDs[cmdName],
+			files:       completionFiles[cmdName],
+		}
+		if name == "help" {
+			spec.words = names
+		}
+		for i := range spec.flags {
+			f := &spec.flags[i]
+			f.values = flagValues(cmdName, *f)
+			f.files = completionFiles[cmdName+" "+f.name]
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// commandFlags returns the flags a command defines, sorted by name
+func commandFlags(command Command) []completionFlag {
+	definer, ok := command.(FlagDefiner)
+	if !ok {
+		return nil
+	}
+	cmd := newFlagSet("")
+	definer.DefineFlags(cmd)
+
+	var flags []completionFlag
+	cmd.VisitAll(func(f *flag.Flag) {
+		flags = append(flags, completionFlag{
+			name:        "-" + f.Name,
+			description: strings.TrimSuffix(strings.SplitN(f.Usage, "\n", 2)[0], " Options:"),
+			takesValue:  !isBoolFlag(f),
+		})
+	})
+	return flags
+}
+
+// isBoolFlag reports whether a flag is a switch that takes no value
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// flagValues returns the values completed for a flag of a command
+func flagValues(cmd string, f completionFlag) []string {
+	if !f.takesValue {
+		return nil
+	}
+
+	switch f.name {
+	case "-priority":
+		values := make([]string, len(task.Priorities))
+		for i, p := range task.Priorities {
+			values[i] = strings.ToLower(p.String())
+		}
+		return values
+	case "-due":
+		// Only the filters take keywords, add and update take dates
+		if cmd == "list" || cmd == "export" {
+			return task.DueKeywords
+		}
+	case "-where":
+		values := make([]string, len(task.FilterKeys))
+		for i, key := range task.FilterKeys {
+			values[i] = key + ":"
+		}
+		return values
+	case "-format":
+		return completionFormats[cmd]
+	case "-by":
+		return []string{"day", "week"}
+	case "-group":
+		return []string{"priority", "tag", "none"}
+	case "-sort":
+		return []string{"id", "priority", "due", "urgency"}
+	}
+	return nil
+}
+
+// bashCompletion generates the bash completion script
+func bashCompletion(specs []completionSpec) string {
+	var sb strings.Builder
+	sb.WriteString(`# bash completion for task, generated by 'task completion bash'
+# Load it with: source <(task completion bash)
+
+_task_ids() {
+    task completion ids "$1" 2>/dev/null | cut -f1
+}
+
+_task() {
+    local cur prev cmd words
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+    COMPREPLY=()
+
+    if [[ $COMP_CWORD -eq 1 ]]; then
+`)
+	fmt.Fprintf(&sb, "        COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(specNames(specs), " "))
+	sb.WriteString(`        return
+    fi
+    cmd="${COMP_WORDS[1]}"
+
+    # Values of the flags
+    case "$cmd $prev" in
+`)
+	for _, s := range specs {
+		for _, f := range s.flags {
+			if !f.takesValue {
+				continue
+			}
+			fmt.Fprintf(&sb, "        %q)\n", s.name+" "+f.name)
+			switch {
+			case f.files:
+				sb.WriteString("            COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+			case len(f.values) > 0:
+				fmt.Fprintf(&sb, "            COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(f.values, " "))
+			}
+			sb.WriteString("            return ;;\n")
+		}
+	}
+	sb.WriteString(`    esac
+
+    # Flags and arguments
+    case "$cmd" in
+`)
+	for _, s := range specs {
+		words := append(flagNames(s.flags), s.words...)
+		fmt.Fprintf(&sb, "        %s)\n", s.name)
+		fmt.Fprintf(&sb, "            words=%q\n", strings.Join(words, " "))
+		if s.ids != "" {
+			fmt.Fprintf(&sb, "            words=\"$words $(_task_ids %s)\"\n", s.ids)
+		}
+		if s.files {
+			sb.WriteString("            COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+		}
+		sb.WriteString("            ;;\n")
+	}
+	sb.WriteString(`    esac
+    COMPREPLY+=($(compgen -W "$words" -- "$cur"))
+}
+
+complete -F _task task
+`)
+	return sb.String()
+}
+
+// zshCompletion generates the zsh completion script
+func zshCompletion(specs []completionSpec) string {
+	var sb strings.Builder
+	sb.WriteString(`#compdef task
+# zsh completion for task, generated by 'task completion zsh'
+# Load it with: source <(task completion zsh)
+
+_task_ids() {
+    local line
+    local -a ids
+    for line in ${(f)"$(task completion ids $1 2>/dev/null)"}; do
+        ids+=("${line%%$'\t'*}:${${line#*$'\t'}//:/\\:}")
+    done
+    _describe -t ids 'task' ids
+}
+
+_task() {
+    local -a commands flags
+    commands=(
+`)
+	for _, s := range specs {
+		fmt.Fprintf(&sb, "        %s\n", zshQuote(s.name+":"+zshEscape(s.description)))
+	}
+	sb.WriteString(`    )
+
+    if (( CURRENT == 2 )); then
+        _describe -t commands 'command' commands
+        return
+    fi
+
+    local cmd=${words[2]} prev=${words[CURRENT-1]}
+
+    # Values of the flags
+    case "$cmd $prev" in
+`)
+	for _, s := range specs {
+		for _, f := range s.flags {
+			if !f.takesValue {
+				continue
+			}
+			fmt.Fprintf(&sb, "        %q)\n", s.name+" "+f.name)
+			switch {
+			case f.files:
+				sb.WriteString("            _files\n")
+			case len(f.values) > 0:
+				fmt.Fprintf(&sb, "            compadd -- %s\n", strings.Join(f.values, " "))
+			default:
+				fmt.Fprintf(&sb, "            _message %s\n", zshQuote(f.description))
+			}
+			sb.WriteString("            return ;;\n")
+		}
+	}
+	sb.WriteString(`    esac
+
+    # Flags and arguments
+    case $cmd in
+`)
+	for _, s := range specs {
+		fmt.Fprintf(&sb, "        %s)\n", s.name)
+		if len(s.flags) > 0 {
+			sb.WriteString("            flags=(")
+			for i, f := range s.flags {
+				if i > 0 {
+					sb.WriteString(" ")
+				}
+				sb.WriteString(zshQuote(f.name + ":" + zshEscape(f.description)))
+			}
+			sb.WriteString(")\n")
+			sb.WriteString("            _describe -t flags 'flag' flags\n")
+		}
+		if len(s.words) > 0 {
+			fmt.Fprintf(&sb, "            compadd -- %s\n", strings.Join(s.words, " "))
+		}
+		if s.ids != "" {
+			fmt.Fprintf(&sb, "            _task_ids %s\n", s.ids)
+		}
+		if s.files {
+			sb.WriteString("            _files\n")
+		}
+		sb.WriteString("            ;;\n")
+	}
+	sb.WriteString(`    esac
+}
+
+compdef _task task
+`)
+	return sb.String()
+}
+
+// fishCompletion generates the fish completion script
+func fishCompletion(specs []completionSpec) string {
+	var sb strings.Builder
+	sb.WriteString(`# fish completion for task, generated by 'task completion fish'
+# Load it with: task completion fish | source
+
+function __task_ids
+    task completion ids $argv 2>/dev/null
+end
+
+complete -c task -f
+`)
+	for _, s := range specs {
+		fmt.Fprintf(&sb, "complete -c task -n __fish_use_subcommand -a %s -d %s\n", s.name, fishQuote(s.description))
+	}
+	for _, s := range specs {
+		cond := fishQuote("__fish_seen_subcommand_from " + s.name)
+		sb.WriteString("\n")
+		for _, f := range s.flags {
+			fmt.Fprintf(&sb, "complete -c task -n %s -o %s", cond, strings.TrimPrefix(f.name, "-"))
+			switch {
+			case f.files:
+				sb.WriteString(" -r -F")
+			case len(f.values) > 0:
+				fmt.Fprintf(&sb, " -x -a %s", fishQuote(strings.Join(f.values, " ")))
+			case f.takesValue:
+				sb.WriteString(" -x")
+			}
+			fmt.Fprintf(&sb, " -d %s\n", fishQuote(f.description))
+		}
+		if len(s.words) > 0 {
+			fmt.Fprintf(&sb, "complete -c task -n %s -a %s\n", cond, fishQuote(strings.Join(s.words, " ")))
+		}
+		if s.ids != "" {
+			fmt.Fprintf(&sb, "complete -c task -n %s -a '(__task_ids %s)'\n", cond, s.ids)
+		}
+		if s.files {
+			fmt.Fprintf(&sb, "complete -c task -n %s -F\n", cond)
+		}
+	}
+	return sb.String()
+}
+
+// specNames returns the names of the commands
+func specNames(specs []completionSpec) []string {
+	names := make([]string, len(specs))
+	for i, s := range specs {
+		names[i] = s.name
+	}
+	return names
+}
+
+// flagNames returns the names of the flags
+func flagNames(flags []completionFlag) []string {
+	names := make([]string, len(flags))
+	for i, f := range flags {
+		names[i] = f.name
+	}
+	return names
+}
+
+// zshEscape escapes the colons of a _describe description
+func zshEscape(s string) string {
+	return strings.ReplaceAll(s, ":", `\:`)
+}
+
+// zshQuote quotes a word for zsh
+func zshQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote quotes a word for fish
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+// Help returns the help message for the completion command
+func (c *CompletionCommand) Help() string {
+	return `Generate shell completion scripts
+
+Usage:
+  task completion bash|zsh|fish
+
+The scripts complete the commands, their flags, the values of flags like
+-priority, -due or -format, and the IDs of the tasks with their titles.
+
+  bash   source <(task completion bash)      in ~/.bashrc
+  zsh    source <(task completion zsh)       in ~/.zshrc, after compinit
+  fish   task completion fish | source       in ~/.config/fish/config.fish
+
+'task completion ids [all|pending|done|trash|archive]' prints the task
+IDs and titles used by the scripts.`
+}
@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/daemon"
+	"github.com/kubaliski/task-cli/internal/task"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// ErrDaemonRunning is returned when a daemon already serves the data directory
+var ErrDaemonRunning = errors.New("the daemon is already running")
+
+type DaemonCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+
+	// Flags, defined by DefineFlags
+	socket *string
+}
+
+// NewDaemonCommand creates a new instance of DaemonCommand
+func NewDaemonCommand(tm task.ITaskManager, p Presenter) *DaemonCommand {
+	return &DaemonCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// DefineFlags defines the flags of the daemon command
+func (c *DaemonCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.socket = cmd.String("socket", "", "Path of the Unix socket (default: daemon.sock in the data directory)")
+}
+
+// Execute executes the daemon command
+func (c *DaemonCommand) Execute(args []string) error {
+	cmd := newFlagSet("daemon")
+	c.DefineFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	if *c.socket == "" {
+		dataDir, err := task.DataDir()
+		if err != nil {
+			return c.presenter.PrintError("error finding data directory: %w", err)
+		}
+		if err := os.MkdirAll(dataDir, 0755); err != nil {
+			return c.presenter.PrintError("error creating data directory: %w", err)
+		}
+		*c.socket = daemon.SocketPath(dataDir)
+	}
+
+	// A c.socket left by a daemon that didn't stop cleanly is replaced
+	if client, err := daemon.Dial(*c.socket); err == nil {
+		client.Close()
+		return c.presenter.PrintError("%w on %s", ErrDaemonRunning, *c.socket)
+	}
+	os.Remove(*c.socket)
+
+	listener, err := net.Listen("unix", *c.socket)
+	if err != nil {
+		return c.presenter.PrintError("error listening on socket: %w", err)
+	}
+	defer os.Remove(*c.socket)
+
+	srv := daemon.NewServer(c.tm)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go watchAging(ctx, c.tm)
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	c.presenter.PrintSuccess("Daemon listening on %s (press Ctrl+C to stop)", *c.socket)
+	if err := srv.Serve(listener); err != nil {
+		return c.presenter.PrintError("error serving tasks: %w", err)
+	}
+	return nil
+}
+
+// watchAging ages the priorities every hour while a long running command
+// runs, printing the errors as they happen. When the tasks come from the
+// daemon it's the daemon that ages them.
+func watchAging(ctx context.Context, tm task.ITaskManager) {
+	local, ok := tm.(*task.TaskManager)
+	if !ok {
+		return
+	}
+	task.WatchAging(ctx, local, time.Hour, func(err error) {
+		fmt.Fprintf(os.Stderr, "Error: error aging priorities: %v\n", err)
+	})
+}
+
+// Help returns the help message for the daemon command
+func (c *DaemonCommand) Help() string {
+	return `Serve the tasks to local clients through a Unix socket
+
+Usage:
+  task daemon [flags]
+
+Flags:
+  -socket string   Path of the Unix socket (default: daemon.sock in the
+                   data directory)
+
+The daemon keeps the tasks in memory and speaks JSON-RPC 2.0, one JSON
+message after another. Its methods are the ones of the task manager, like
+AddTask, GetTaskByID, CompleteTask or GetTasksSorted, with their arguments
+in an object: {"jsonrpc": "2.0", "id": 1, "method": "CompleteTask",
+"params": {"id": 3, "note": "Released"}}. Changes are saved right away.
+
+Calling Subscribe makes the daemon send an "Event" notification with the
+action, the task and the time of every change, until Unsubscribe.
+
+While the daemon runs on the default socket, the other task commands use
+it instead of reading the task files.`
+}
@@ -2,13 +2,15 @@ package commands
 
 import (
 	"flag"
-	"strconv"
-	"task-cli/internal/task"
+	"github.com/kubaliski/task-cli/internal/task"
 )
 
 type DeleteCommand struct {
 	tm        task.ITaskManager
 	presenter Presenter
+
+	// Flags, defined by DefineFlags
+	bulk *bulkFlags
 }
 
 // NewDeleteCommand creates a new instance of DeleteCommand
@@ -19,46 +21,68 @@ func NewDeleteCommand(tm task.ITaskManager, p Presenter) *DeleteCommand {
 	}
 }
 
+// DefineFlags defines the flags of the delete command
+func (c *DeleteCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.bulk = addBulkFlags(cmd)
+}
+
 // Execute executes the delete command
 func (c *DeleteCommand) Execute(args []string) error {
-	cmd := flag.NewFlagSet("delete", flag.ExitOnError)
-	if err := cmd.Parse(args); err != nil {
-		return c.presenter.PrintError("error parsing arguments: %v", err)
-	}
+	cmd := newFlagSet("delete")
+	c.DefineFlags(cmd)
 
-	if len(cmd.Args()) == 0 {
-		return c.presenter.PrintError("task ID is required")
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
 	}
+	idArgs := cmd.Args()
 
-	id, err := strconv.Atoi(cmd.Args()[0])
+	// Verify that all the tasks exist
+	targets, err := resolveTargets(c.tm, c.presenter, idArgs, *c.bulk.where)
 	if err != nil {
-		return c.presenter.PrintError("invalid task ID: %v", err)
+		return err
 	}
 
-	// Verify if the task exists
-	if _, err := c.tm.GetTaskByID(id); err != nil {
-		return c.presenter.PrintError("task not found: %v", err)
+	ok, err := confirmBulk(c.presenter, "deleted", targets, c.bulk)
+	if err != nil || !ok {
+		return err
 	}
 
-	if err := c.tm.DeleteTask(id); err != nil {
-		return c.presenter.PrintError("error deleting task: %v", err)
+	for _, t := range targets {
+		if err := c.tm.DeleteTask(t.ID); err != nil {
+			return c.presenter.PrintError("error deleting task: %w", err)
+		}
 	}
 
 	if err := c.tm.SaveTasks(); err != nil {
-		return c.presenter.PrintError("error saving changes: %v", err)
+		return c.presenter.PrintError("error saving changes: %w", err)
 	}
 
-	c.presenter.PrintSuccess("Task %d deleted successfully", id)
+	if len(targets) == 1 {
+		id := targets[0].ID
+		c.presenter.PrintSuccess("Task %d moved to trash (use 'task restore %d' to undo)", id, id)
+	} else {
+		c.presenter.PrintSuccess("%d tasks moved to trash", len(targets))
+	}
 	return nil
 }
 
 // Help returns the help message for the delete command
 func (c *DeleteCommand) Help() string {
-	return `Remove a task
+	return `Move tasks to the trash
 
 Usage:
-  task delete <id>
+  task delete <ids> [flags]
+  task delete -where <filter> [flags]
 
 Arguments:
-  <id>    The ID of the task to delete`
+  <ids>    Task IDs and ranges, e.g. 3 or 3,5,8-12
+
+Flags:
+  -where string   Delete the tasks matching a filter expression
+                  (see 'task help list')
+  -dry-run        Show the affected tasks without deleting them
+  -yes            Don't ask for confirmation
+
+Deleted tasks can be recovered with 'task restore <id>'
+and permanently removed with 'task trash empty'.`
 }
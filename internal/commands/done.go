@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"flag"
+	"github.com/kubaliski/task-cli/internal/task"
+)
+
+type DoneCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+}
+
+// NewDoneCommand creates a new instance of DoneCommand
+func NewDoneCommand(tm task.ITaskManager, p Presenter) *DoneCommand {
+	return &DoneCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// DefineFlags defines the flags of the done command
+func (c *DoneCommand) DefineFlags(cmd *flag.FlagSet) {
+	addStatusFlags(cmd, c.change())
+}
+
+// Execute executes the done command
+func (c *DoneCommand) Execute(args []string) error {
+	return runStatusChange(c.tm, c.presenter, args, c.change())
+}
+
+// change describes what the done command does to the tasks
+func (c *DoneCommand) change() statusChange {
+	return statusChange{
+		name:     "done",
+		noteHelp: "Completion note",
+		done:     true,
+		action:   "completed",
+		skipped:  "is already completed",
+		failure:  "error completing task",
+		apply:    c.tm.CompleteTask,
+	}
+}
+
+// Help returns the help message for the done command
+func (c *DoneCommand) Help() string {
+	return `Mark tasks as completed
+
+Usage:
+  task done <ids> [flags]
+  task done -where <filter> [flags]
+
+Arguments:
+  <ids>    Task IDs and ranges, e.g. 3 or 3,5,8-12
+
+Flags:
+  -note string    Completion note
+  -where string   Complete the tasks matching a filter expression
+  -dry-run        Show the affected tasks without changing them
+  -yes            Don't ask for confirmation`
+}
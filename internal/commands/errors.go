@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors of the commands themselves, check for them with errors.Is
+var (
+	// ErrUnknownCommand is returned for a command that doesn't exist
+	ErrUnknownCommand = errors.New("unknown command")
+	// ErrUsage is returned when a command has missing or conflicting arguments
+	ErrUsage = errors.New("invalid usage")
+)
+
+// usageError is an error in the arguments of a command
+type usageError struct {
+	msg string
+}
+
+// Error implements the error interface
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// Is reports whether the target is ErrUsage
+func (e *usageError) Is(target error) bool {
+	return target == ErrUsage
+}
+
+// newUsageError returns an error in the arguments of a command
+func newUsageError(format string, a ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, a...)}
+}
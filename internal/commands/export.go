@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"flag"
+	"github.com/kubaliski/task-cli/internal/formats"
+	"github.com/kubaliski/task-cli/internal/task"
+	"os"
+)
+
+type ExportCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+
+	// Flags, defined by DefineFlags
+	format  *string
+	groupBy *string
+	output  *string
+	filters *listFilters
+}
+
+// NewExportCommand creates a new instance of ExportCommand
+func NewExportCommand(tm task.ITaskManager, p Presenter) *ExportCommand {
+	return &ExportCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// DefineFlags defines the flags of the export command
+func (c *ExportCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.format = cmd.String("format", "ics", "Export format: ics, todotxt or markdown")
+	c.groupBy = cmd.String("group", formats.GroupByPriority, "Markdown grouping: priority, tag or none")
+	c.output = cmd.String("o", "", "Output file (default: standard output)")
+	c.filters = addListFilters(cmd)
+}
+
+// Execute executes the export command
+func (c *ExportCommand) Execute(args []string) error {
+	cmd := newFlagSet("export")
+	c.DefineFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	var encode formats.Encoder
+	switch *c.format {
+	case "ics":
+		encode = formats.EncodeICS
+	case "todotxt":
+		encode = formats.EncodeTodoTxt
+	case "markdown", "md":
+		e, err := formats.MarkdownEncoder(*c.groupBy)
+		if err != nil {
+			return c.presenter.PrintError("invalid grouping: %w", err)
+		}
+		encode = e
+	default:
+		return c.presenter.PrintError("%w", newUsageError("unknown export format: %s", *c.format))
+	}
+
+	tasks, err := c.filters.tasks(c.tm, c.presenter)
+	if err != nil {
+		return err
+	}
+
+	if *c.output == "" {
+		if err := encode(os.Stdout, tasks); err != nil {
+			return c.presenter.PrintError("error exporting tasks: %w", err)
+		}
+		return nil
+	}
+
+	f, err := os.Create(*c.output)
+	if err != nil {
+		return c.presenter.PrintError("error creating file: %w", err)
+	}
+	if err := encode(f, tasks); err != nil {
+		f.Close()
+		return c.presenter.PrintError("error exporting tasks: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return c.presenter.PrintError("error writing file: %w", err)
+	}
+
+	c.presenter.PrintSuccess("%d task(s) exported to %s", len(tasks), *c.output)
+	return nil
+}
+
+// Help returns the help message for the export command
+func (c *ExportCommand) Help() string {
+	return `Export tasks to other applications
+
+Usage:
+  task export [flags]
+
+Flags:
+  -format string   Export format (default: ics)
+                     ics        iCalendar VTODO entries with due dates,
+                                reminders as alarms, priority and status
+                     todotxt    todo.txt lines, tags as +projects
+                     markdown   GitHub-style checklist
+  -group string    Markdown sections: priority, tag or none (default: priority)
+  -o string        Output file (default: standard output)
+
+Tasks are selected like in 'task list': pending tasks by default, and the
+-all, -archived, -due, -where, -priority and -by-due flags are accepted.`
+}
@@ -2,13 +2,15 @@ package commands
 
 import (
 	"flag"
-	"strconv"
-	"task-cli/internal/task"
+	"github.com/kubaliski/task-cli/internal/task"
 )
 
 type GetCommand struct {
 	tm        task.ITaskManager
 	presenter Presenter
+
+	// Flags, defined by DefineFlags
+	history *bool
 }
 
 // NewGetCommand creates a new instance of GetCommand
@@ -19,28 +21,43 @@ func NewGetCommand(tm task.ITaskManager, p Presenter) *GetCommand {
 	}
 }
 
+// DefineFlags defines the flags of the get command
+func (c *GetCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.history = cmd.Bool("history", false, "Show the change history of the task")
+}
+
 // Execute executes the get command
 func (c *GetCommand) Execute(args []string) error {
-	cmd := flag.NewFlagSet("get", flag.ExitOnError)
-	if err := cmd.Parse(args); err != nil {
-		return c.presenter.PrintError("error parsing arguments: %v", err)
+	cmd := newFlagSet("get")
+	c.DefineFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
 	}
+	idArgs := cmd.Args()
 
-	if len(cmd.Args()) == 0 {
-		return c.presenter.PrintError("task ID is required")
+	if len(idArgs) == 0 {
+		return c.presenter.PrintError("%w", newUsageError("task ID is required"))
 	}
 
-	id, err := strconv.Atoi(cmd.Args()[0])
+	tasks, err := resolveTargets(c.tm, c.presenter, idArgs, "")
 	if err != nil {
-		return c.presenter.PrintError("invalid task ID: %v", err)
+		return err
 	}
 
-	t, err := c.tm.GetTaskByID(id)
-	if err != nil {
-		return c.presenter.PrintError("error getting task: %v", err)
+	if *c.history {
+		for _, t := range tasks {
+			if err := c.presenter.PrintHistory(t); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
-	return c.presenter.PrintTask(t)
+	if len(tasks) == 1 {
+		return c.presenter.PrintTask(tasks[0])
+	}
+	return c.presenter.PrintTaskList(tasks)
 }
 
 // Help returns the help message for the get command
@@ -48,8 +65,11 @@ func (c *GetCommand) Help() string {
 	return `Show detailed task information
 
 Usage:
-  task get <id>
+  task get <ids> [flags]
 
 Arguments:
-  <id>    The ID of the task to display`
+  <ids>    Task IDs and ranges to display, e.g. 3 or 3,5,8-12
+
+Flags:
+  -history   Show who changed each field and when`
 }
@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"flag"
 	"strings"
 )
 
@@ -20,9 +19,9 @@ func NewHelpCommand(commands map[string]Command, p Presenter) *HelpCommand {
 
 // Execute executes the help command
 func (c *HelpCommand) Execute(args []string) error {
-	cmd := flag.NewFlagSet("help", flag.ExitOnError)
-	if err := cmd.Parse(args); err != nil {
-		return c.presenter.PrintError("error parsing arguments: %v", err)
+	cmd := newFlagSet("help")
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
 	}
 
 	// If a command is provided, show help for that command
@@ -36,9 +35,10 @@ func (c *HelpCommand) Execute(args []string) error {
 
 // ShowCommandHelp shows help for a specific command
 func (c *HelpCommand) showCommandHelp(commandName string) error {
+	commandName = resolveAlias(commandName)
 	cmd, exists := c.commands[commandName]
 	if !exists {
-		return c.presenter.PrintError("unknown command: %s", commandName)
+		return c.presenter.PrintError("%w: %s", ErrUnknownCommand, commandName)
 	}
 
 	help := cmd.Help()
@@ -61,9 +61,32 @@ func (c *HelpCommand) showGeneralHelp() error {
 	}{
 		{"add", "Create a new task"},
 		{"list", "List and filter tasks"},
+		{"next", "Show the most urgent task to work on"},
 		{"update", "Update an existing task"},
-		{"delete", "Remove a task"},
+		{"done", "Mark tasks as completed"},
+		{"reopen", "Mark completed tasks as pending again"},
+		{"start", "Start tracking time on a task"},
+		{"stop", "Stop the running timer"},
+		{"log", "Log time spent on a task"},
+		{"calendar", "Show a month calendar of due tasks"},
+		{"agenda", "List what is due in the next days"},
+		{"stats", "Show task statistics"},
+		{"report", "Show a productivity report"},
+		{"export", "Export tasks to other applications"},
+		{"import", "Import tasks from other applications"},
+		{"delete", "Move a task to the trash"},
 		{"get", "Show detailed task information"},
+		{"trash", "List or empty deleted tasks"},
+		{"restore", "Restore deleted tasks or a backup"},
+		{"backup", "Back up all the task data"},
+		{"sync", "Synchronize the tasks with a git remote"},
+		{"merge", "Merge the tasks file of another machine"},
+		{"serve", "Serve the tasks through a REST API"},
+		{"daemon", "Serve the tasks to local clients through a Unix socket"},
+		{"hooks", "Show, test and inspect the hooks run on task events"},
+		{"archive", "Move completed tasks to the archive"},
+		{"unarchive", "Move a task back from the archive"},
+		{"completion", "Generate shell completion scripts"},
 		{"help", "Show help about any command"},
 	}
 
@@ -80,6 +103,21 @@ func (c *HelpCommand) showGeneralHelp() error {
 		sb.WriteString(c.formatCommandHelp(cmd.name, cmd.description, maxWidth))
 	}
 
+	sb.WriteString("\nAliases:\n")
+	sb.WriteString("  ls    list\n")
+	sb.WriteString("  rm    delete\n")
+
+	sb.WriteString("\nGlobal Flags:\n")
+	sb.WriteString("  --db string       Data directory (default: ~/.task-cli)\n")
+	sb.WriteString("  --color string    Color the output: auto, always or never (default: auto)\n")
+	sb.WriteString("  --format string   Show tasks as table, list or json, given before the command\n")
+	sb.WriteString("  --json            Print errors as JSON\n")
+
+	sb.WriteString("\nShort Flags:\n")
+	sb.WriteString("  -a all, -d due, -e estimate, -f format, -n note, -p priority,\n")
+	sb.WriteString("  -r reminder, -t title, -w where, -y yes\n")
+	sb.WriteString("  Flags can come before or after the task IDs, use -- to end them.\n")
+
 	sb.WriteString("\nUse 'task help <command>' for more information about a command\n")
 
 	c.presenter.PrintSuccess(sb.String())
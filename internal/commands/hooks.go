@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"flag"
+	"github.com/kubaliski/task-cli/internal/config"
+	"github.com/kubaliski/task-cli/internal/hooks"
+	"github.com/kubaliski/task-cli/internal/task"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type HooksCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+
+	// Flags of hooks log, defined by DefineFlags
+	lines *int
+}
+
+// NewHooksCommand creates a new instance of HooksCommand
+func NewHooksCommand(tm task.ITaskManager, p Presenter) *HooksCommand {
+	return &HooksCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// DefineFlags defines the flags of the hooks subcommands, only log has one
+func (c *HooksCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.lines = cmd.Int("n", 20, "Number of runs to show")
+}
+
+// Execute executes the hooks command
+func (c *HooksCommand) Execute(args []string) error {
+	if len(args) == 0 {
+		return c.list()
+	}
+
+	switch args[0] {
+	case "list":
+		return c.list()
+	case "log":
+		return c.log(args[1:])
+	case "test":
+		return c.test(args[1:])
+	default:
+		return c.presenter.PrintError("%w", newUsageError("unknown hooks subcommand: %s", args[0]))
+	}
+}
+
+// list shows the configured hooks
+func (c *HooksCommand) list() error {
+	dataDir, cfg, err := c.config()
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Hooks) == 0 {
+		c.presenter.PrintSuccess("No hooks configured in %s", config.Path(dataDir))
+		return nil
+	}
+
+	for i, h := range cfg.Hooks {
+		name := h.Name
+		if name == "" {
+			name = "hook " + strconv.Itoa(i+1)
+		}
+		c.presenter.PrintSuccess("%s: on %s run %s (timeout %s, %d retries)",
+			name, strings.Join(h.Events, ", "), h.Target(), h.TimeoutOrDefault(), h.Retries)
+	}
+	return nil
+}
+
+// log shows the last runs of the hooks
+func (c *HooksCommand) log(args []string) error {
+	cmd := newFlagSet("hooks log")
+	c.DefineFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	dataDir, err := task.DataDir()
+	if err != nil {
+		return c.presenter.PrintError("error finding data directory: %w", err)
+	}
+
+	data, err := os.ReadFile(hooks.LogPath(dataDir))
+	if os.IsNotExist(err) || len(data) == 0 {
+		c.presenter.PrintSuccess("No hooks have run yet")
+		return nil
+	}
+	if err != nil {
+		return c.presenter.PrintError("error reading hook log: %w", err)
+	}
+
+	entries := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if *c.lines > 0 && len(entries) > *c.lines {
+		entries = entries[len(entries)-*c.lines:]
+	}
+	c.presenter.PrintSuccess("%s", strings.Join(entries, "\n"))
+	return nil
+}
+
+// test runs the hooks of an event for a task and waits for them
+func (c *HooksCommand) test(args []string) error {
+	if len(args) < 2 {
+		return c.presenter.PrintError("%w", newUsageError("event and task ID are required"))
+	}
+
+	event := args[0]
+	if !config.IsEvent(event) {
+		return c.presenter.PrintError("%w", newUsageError("unknown event: %s (use %s)", event, strings.Join(config.Events, ", ")))
+	}
+
+	id, err := parseID(args[1])
+	if err != nil {
+		return c.presenter.PrintError("%w", err)
+	}
+	t, err := c.tm.GetTaskByID(id)
+	if err != nil {
+		return c.presenter.PrintError("error getting task: %w", err)
+	}
+
+	dataDir, cfg, err := c.config()
+	if err != nil {
+		return err
+	}
+
+	runner := hooks.New(dataDir, cfg.Hooks)
+	n := runner.Test(event, t)
+	if n == 0 {
+		c.presenter.PrintSuccess("No hooks for the %s event", event)
+		return nil
+	}
+	c.presenter.PrintSuccess("Ran %d hook(s) for task %d, results:", n, id)
+	return c.log([]string{"-n", strconv.Itoa(n)})
+}
+
+// config loads the configuration of the data directory
+func (c *HooksCommand) config() (string, config.Config, error) {
+	dataDir, err := task.DataDir()
+	if err != nil {
+		return "", config.Config{}, c.presenter.PrintError("error finding data directory: %w", err)
+	}
+	cfg, err := config.Load(dataDir)
+	if err != nil {
+		return "", config.Config{}, c.presenter.PrintError("error loading configuration: %w", err)
+	}
+	return dataDir, cfg, nil
+}
+
+// Help returns the help message for the hooks command
+func (c *HooksCommand) Help() string {
+	return `Show, test and inspect the hooks run on task events
+
+Usage:
+  task hooks [list]
+  task hooks log [-n 20]
+  task hooks test <event> <id>
+
+Subcommands:
+  list                 Show the configured hooks
+  log                  Show the last hook runs and their results
+  test <event> <id>    Run the hooks of an event for a task and show
+                       the results
+
+Hooks are configured in config.json in the data directory:
+
+  {"hooks": [
+    {"name": "chat", "events": ["complete"], "url": "https://chat.example.com/hook",
+     "timeout": "5s", "retries": 2},
+    {"events": ["add", "update", "delete"], "command": "task list > ~/tasks.txt"}
+  ]}
+
+Events: add, update, complete, delete, purge (removed from the trash or by
+a merge) and overdue. Commands run with sh, they get the event as JSON on
+their standard input and TASK_EVENT, TASK_ACTION, TASK_ID and TASK_TITLE
+in their environment. URLs get the same JSON in a POST request, any status
+other than 2xx is a failure. Every attempt is limited by the timeout
+(default: 10s) and failed hooks are retried, waiting a bit longer each
+time. A hook with 64 runs waiting drops the new events, logging them.
+
+Overdue hooks fire once per task when a command runs after its due date,
+or within a minute while the daemon or the API server is running.`
+}
@@ -0,0 +1,284 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/formats"
+	"github.com/kubaliski/task-cli/internal/task"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type ImportCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+
+	// Flags, defined by DefineFlags
+	format          *string
+	columns         *string
+	allowDuplicates *bool
+	dryRun          *bool
+}
+
+// importResult counts what happened to the imported tasks
+type importResult struct {
+	created int
+	updated int
+	skipped []string
+}
+
+// NewImportCommand creates a new instance of ImportCommand
+func NewImportCommand(tm task.ITaskManager, p Presenter) *ImportCommand {
+	return &ImportCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// DefineFlags defines the flags of the import command
+func (c *ImportCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.format = cmd.String("format", "", "Import format: ics, taskwarrior, todotxt or csv (default: from the file extension)")
+	c.columns = cmd.String("map", "", "CSV column mapping, e.g. \"title=Name,due=Deadline\"")
+	c.allowDuplicates = cmd.Bool("allow-duplicates", false, "Import tasks whose title already exists")
+	c.dryRun = cmd.Bool("dry-run", false, "Show what would be imported without saving")
+}
+
+// Execute executes the import command
+func (c *ImportCommand) Execute(args []string) error {
+	cmd := newFlagSet("import")
+	c.DefineFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+	fileArgs := cmd.Args()
+
+	if len(fileArgs) == 0 {
+		return c.presenter.PrintError("%w", newUsageError("file to import is required"))
+	}
+	fileName := fileArgs[0]
+
+	if *c.format == "" {
+		*c.format = formatFromExtension(fileName)
+	}
+
+	var decode formats.Decoder
+	switch *c.format {
+	case "ics":
+		decode = formats.DecodeICS
+	case "taskwarrior":
+		decode = formats.DecodeTaskwarrior
+	case "todotxt":
+		decode = formats.DecodeTodoTxt
+	case "csv":
+		mapping, err := formats.ParseCSVMapping(*c.columns)
+		if err != nil {
+			return c.presenter.PrintError("invalid column mapping: %w", err)
+		}
+		decode = formats.CSVDecoder(mapping)
+	default:
+		return c.presenter.PrintError("%w", newUsageError("unknown import format: %s", *c.format))
+	}
+
+	f, err := os.Open(fileName)
+	if err != nil {
+		return c.presenter.PrintError("error opening file: %w", err)
+	}
+	defer f.Close()
+
+	incoming, rowErrors, err := decode(f)
+	if err != nil {
+		return c.presenter.PrintError("error reading %s: %w", fileName, err)
+	}
+
+	// A dry run imports into a copy of the tasks, so nothing is changed,
+	// saved or notified to hooks and to the daemon
+	target := c.tm
+	if *c.dryRun {
+		target = task.Snapshot(c.tm)
+	}
+
+	result := importTasks(target, incoming, *c.allowDuplicates)
+	for _, rowErr := range rowErrors {
+		result.skipped = append(result.skipped, "invalid "+rowErr.Error())
+	}
+
+	for _, reason := range result.skipped {
+		c.presenter.PrintSuccess("Skipped %s", reason)
+	}
+
+	if *c.dryRun {
+		c.presenter.PrintSuccess("Dry run: %d task(s) would be created, %d updated, %d skipped",
+			result.created, result.updated, len(result.skipped))
+		return nil
+	}
+
+	if err := c.tm.SaveTasks(); err != nil {
+		return c.presenter.PrintError("error saving tasks: %w", err)
+	}
+
+	c.presenter.PrintSuccess("Import finished: %d created, %d updated, %d skipped",
+		result.created, result.updated, len(result.skipped))
+	return nil
+}
+
+// importTasks updates the active tasks with the same UID as the incoming
+// ones and creates the rest, skipping those whose title already exists.
+// Incoming tasks with the UID of an archived or trashed task are skipped,
+// they are updated once the task is unarchived or restored.
+func importTasks(tm task.ITaskManager, incoming []task.Task, allowDuplicates bool) importResult {
+	var result importResult
+
+	byUID := make(map[string]task.Task)
+	byTitle := make(map[string]task.Task)
+	for _, t := range tm.GetTasksSorted(false, false) {
+		byUID[formats.TaskUID(t)] = t
+		byTitle[titleKey(t.Title)] = t
+	}
+	// Why the tasks that can't be updated are skipped, by UID
+	unavailable := make(map[string]string)
+	for _, t := range tm.GetArchivedTasks() {
+		unavailable[formats.TaskUID(t)] = fmt.Sprintf("task %d is archived, unarchive it to update it", t.ID)
+		byTitle[titleKey(t.Title)] = t
+	}
+	for _, t := range tm.GetTrashedTasks() {
+		unavailable[formats.TaskUID(t)] = fmt.Sprintf("task %d is in the trash, restore it to update it", t.ID)
+	}
+
+	for _, in := range incoming {
+		if reason, ok := unavailable[in.UID]; ok && in.UID != "" {
+			result.skipped = append(result.skipped, fmt.Sprintf("%q: %s", in.Title, reason))
+			continue
+		}
+		if current, ok := byUID[in.UID]; ok && in.UID != "" {
+			if err := updateFromImport(tm, current, in); err != nil {
+				result.skipped = append(result.skipped, fmt.Sprintf("%q: %v", in.Title, err))
+				continue
+			}
+			result.updated++
+			continue
+		}
+
+		if duplicate, ok := byTitle[titleKey(in.Title)]; ok && !allowDuplicates {
+			result.skipped = append(result.skipped, fmt.Sprintf("%q: duplicate of task %d", in.Title, duplicate.ID))
+			continue
+		}
+
+		created, err := tm.ImportTask(in)
+		if err != nil {
+			result.skipped = append(result.skipped, fmt.Sprintf("%q: %v", in.Title, err))
+			continue
+		}
+		if created.UID != "" {
+			byUID[created.UID] = created
+		}
+		byTitle[titleKey(created.Title)] = created
+		result.created++
+	}
+
+	return result
+}
+
+// titleKey returns the form of a title used to find duplicates
+func titleKey(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
+
+// formatFromExtension guesses the import format from the file name
+func formatFromExtension(fileName string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".ics":
+		return "ics"
+	case ".json":
+		return "taskwarrior"
+	case ".txt":
+		return "todotxt"
+	case ".csv":
+		return "csv"
+	default:
+		return ""
+	}
+}
+
+// updateFromImport updates an existing task with the imported values
+func updateFromImport(tm task.ITaskManager, current, in task.Task) error {
+	if in.Title == "" {
+		return task.ErrTitleRequired
+	}
+	// Validate before changing anything
+	if err := task.ValidateTimeOrder(in.DueDate, in.Reminder); err != nil {
+		return err
+	}
+
+	if err := tm.UpdateTask(current.ID, in.Title, in.Done, &in.Priority); err != nil {
+		return err
+	}
+	if err := tm.SetTags(current.ID, in.Tags); err != nil {
+		return err
+	}
+
+	reminderChanged := !sameTime(current.Reminder, in.Reminder)
+	if reminderChanged && current.Reminder != nil {
+		if err := tm.RemoveReminder(current.ID); err != nil {
+			return err
+		}
+	}
+
+	if !sameTime(current.DueDate, in.DueDate) {
+		var err error
+		if in.DueDate == nil {
+			err = tm.RemoveDueDate(current.ID)
+		} else {
+			err = tm.SetDueDate(current.ID, *in.DueDate)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if reminderChanged && in.Reminder != nil {
+		if err := tm.SetReminder(current.ID, *in.Reminder); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sameTime reports whether two optional times are equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// Help returns the help message for the import command
+func (c *ImportCommand) Help() string {
+	return `Import tasks from other applications
+
+Usage:
+  task import <file> [flags]
+
+Arguments:
+  <file>    File to import
+
+Flags:
+  -format string      Import format, by default taken from the file extension
+                        ics           iCalendar VTODO entries (.ics)
+                        taskwarrior   JSON from Taskwarrior's "task export" (.json)
+                        todotxt       todo.txt lists (.txt)
+                        csv           CSV with a header row (.csv)
+  -map string         CSV column mapping, e.g. "title=Name,due=Deadline".
+                      Fields: title, priority, due, reminder, tags, done,
+                      created. Unmapped fields use the column with their name
+  -allow-duplicates   Import tasks whose title already exists
+  -dry-run            Show what would be imported without saving
+
+Tasks with a known UID (iCalendar, Taskwarrior) are updated instead of
+created. Those of an archived or trashed task are skipped until it is
+unarchived or restored. Invalid entries and duplicates are reported and
+skipped.`
+}
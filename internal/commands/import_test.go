@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"github.com/kubaliski/task-cli/internal/task"
+	"strings"
+	"testing"
+)
+
+func TestImportTasksByUID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming task.Task
+		created  int
+		updated  int
+		skipped  string // Part of the reason the task is skipped
+	}{
+		{"new UID", task.Task{UID: "new", Title: "New"}, 1, 0, ""},
+		{"active task", task.Task{UID: "active", Title: "Active renamed"}, 0, 1, ""},
+		{"archived task", task.Task{UID: "archived", Title: "Archived renamed"}, 0, 0, "archived"},
+		{"trashed task", task.Task{UID: "trashed", Title: "Trashed renamed"}, 0, 0, "trash"},
+		{"duplicate title", task.Task{UID: "other", Title: "active"}, 0, 0, "duplicate"},
+		{"no UID", task.Task{Title: "Without UID"}, 1, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := task.NewTaskManagerAt(t.TempDir())
+			for _, uid := range []string{"active", "archived", "trashed"} {
+				if _, err := tm.ImportTask(task.Task{UID: uid, Title: uid, Done: uid == "archived"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := tm.ArchiveTasks([]int{2}, 0); err != nil {
+				t.Fatal(err)
+			}
+			if err := tm.DeleteTask(3); err != nil {
+				t.Fatal(err)
+			}
+
+			result := importTasks(tm, []task.Task{tt.incoming}, false)
+			if result.created != tt.created || result.updated != tt.updated {
+				t.Errorf("created %d, updated %d, want %d and %d", result.created, result.updated, tt.created, tt.updated)
+			}
+			if tt.skipped == "" {
+				if len(result.skipped) > 0 {
+					t.Errorf("skipped %v", result.skipped)
+				}
+			} else if len(result.skipped) != 1 || !strings.Contains(result.skipped[0], tt.skipped) {
+				t.Errorf("skipped %v, want a reason with %q", result.skipped, tt.skipped)
+			}
+
+			// Every UID is still used by a single task
+			uids := make(map[string]int)
+			tasks := append(tm.GetTasksSorted(false, false), tm.GetArchivedTasks()...)
+			for _, task := range append(tasks, tm.GetTrashedTasks()...) {
+				if other, ok := uids[task.UID]; ok {
+					t.Errorf("tasks %d and %d share the UID %s", other, task.ID, task.UID)
+				}
+				uids[task.UID] = task.ID
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package commands
 
-import "task-cli/internal/task"
+import (
+	"flag"
+	"github.com/kubaliski/task-cli/internal/task"
+)
 
 // Command defind the base interface for all commands
 type Command interface {
@@ -10,6 +13,14 @@ type Command interface {
 	Help() string
 }
 
+// FlagDefiner is implemented by the commands that have flags, the shell
+// completion reads them from it
+type FlagDefiner interface {
+	// DefineFlags defines the flags of the command on a flag set, those of
+	// every subcommand for the commands that have them
+	DefineFlags(cmd *flag.FlagSet)
+}
+
 // Commands is a map of commands to their names
 type Commands map[string]Command
 
@@ -21,10 +32,16 @@ type Presenter interface {
 	PrintTaskList(tasks []task.Task) error
 	// PrintTask shows an individual task in a detailed format
 	PrintTask(t task.Task) error
+	// PrintHistory shows the change history of a task
+	PrintHistory(t task.Task) error
+	// PrintJSON shows a value as indented JSON
+	PrintJSON(v interface{}) error
 	// PrintSuccess shows a success message
 	PrintSuccess(format string, a ...interface{})
 	// PrintError shows an error message
 	PrintError(format string, a ...interface{}) error
+	// Confirm asks the user a yes/no question
+	Confirm(format string, a ...interface{}) bool
 }
 
 // TaskFilter defines the interface for filtering tasks
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"flag"
+	"github.com/kubaliski/task-cli/internal/task"
+)
+
+// listFilters holds the sorting and filtering flags shared by the commands
+// that select tasks the way list does
+type listFilters struct {
+	byPriority    *bool
+	byDueDate     *bool
+	sortBy        *string
+	due           *string
+	where         *string
+	showCompleted *bool
+	showArchived  *bool
+}
+
+// addListFilters registers the list sorting and filtering flags on a flag set
+func addListFilters(cmd *flag.FlagSet) *listFilters {
+	return &listFilters{
+		// Order flags
+		byPriority: cmd.Bool("priority", false, "Sort tasks by priority"),
+		byDueDate:  cmd.Bool("by-due", false, "Sort by due date"),
+		sortBy:     cmd.String("sort", "", "Sort by id, priority, due or urgency"),
+
+		// Due time flags
+		due: cmd.String("due", "",
+			`Filter tasks by due date. Options:
+         - "today":     Due today
+         - "tomorrow":  Due tomorrow
+         - "thisweek":  Due this week
+         - "nextweek":  Due next week
+         - "overdue":   Overdue tasks
+         - "duesoon":   Tasks due soon
+         - "upcoming":  Tasks with upcoming reminders
+         - Or specify a date: "2024-01-20 15:00"`),
+
+		// Other flags
+		where:         cmd.String("where", "", "Filter expression, e.g. \"tag:backend priority:high\""),
+		showCompleted: cmd.Bool("all", false, "Show completed tasks"),
+		showArchived:  cmd.Bool("archived", false, "Show archived tasks instead of active ones"),
+	}
+}
+
+// tasks returns the sorted tasks that match the filters
+func (f *listFilters) tasks(tm task.ITaskManager, p Presenter) ([]task.Task, error) {
+	switch *f.sortBy {
+	case "", "id", "priority", "due", "urgency":
+	default:
+		return nil, p.PrintError("%w", newUsageError("unknown sort: %s (use id, priority, due or urgency)", *f.sortBy))
+	}
+
+	tasks, err := task.ListTasks(tm, f.options())
+	if err != nil {
+		return nil, p.PrintError("%w", err)
+	}
+	return tasks, nil
+}
+
+// options returns the filters as task list options
+func (f *listFilters) options() task.ListOptions {
+	return task.ListOptions{
+		ByPriority: *f.byPriority || *f.sortBy == "priority",
+		ByDueDate:  *f.byDueDate || *f.sortBy == "due",
+		ByUrgency:  *f.sortBy == "urgency",
+		Due:        *f.due,
+		Where:      *f.where,
+		All:        *f.showCompleted,
+		Archived:   *f.showArchived,
+	}
+}
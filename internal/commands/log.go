@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"flag"
+	"github.com/kubaliski/task-cli/internal/task"
+)
+
+type LogCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+
+	// Flags, defined by DefineFlags
+	note *string
+}
+
+// NewLogCommand creates a new instance of LogCommand
+func NewLogCommand(tm task.ITaskManager, p Presenter) *LogCommand {
+	return &LogCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// DefineFlags defines the flags of the log command
+func (c *LogCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.note = cmd.String("note", "", "What the time was spent on")
+}
+
+// Execute executes the log command
+func (c *LogCommand) Execute(args []string) error {
+	cmd := newFlagSet("log")
+	c.DefineFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+	positional := cmd.Args()
+
+	if len(positional) < 2 {
+		return c.presenter.PrintError("%w", newUsageError("task ID and duration are required"))
+	}
+
+	id, err := parseID(positional[0])
+	if err != nil {
+		return c.presenter.PrintError("%w", err)
+	}
+
+	d, err := task.ParseDuration(positional[1])
+	if err != nil {
+		return c.presenter.PrintError("invalid duration: %w", err)
+	}
+
+	if err := c.tm.LogTime(id, d, *c.note); err != nil {
+		return c.presenter.PrintError("error logging time: %w", err)
+	}
+
+	if err := c.tm.SaveTasks(); err != nil {
+		return c.presenter.PrintError("error saving changes: %w", err)
+	}
+
+	c.presenter.PrintSuccess("Logged %s on task %d", task.FormatDuration(d), id)
+	return nil
+}
+
+// Help returns the help message for the log command
+func (c *LogCommand) Help() string {
+	return `Log time spent on a task manually
+
+Usage:
+  task log <id> <duration> [flags]
+
+Arguments:
+  <id>          The ID of the task
+  <duration>    Time spent, e.g. 45m, 1h30m
+
+Flags:
+  -note string   What the time was spent on`
+}
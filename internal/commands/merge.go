@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"os"
+)
+
+type MergeCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+
+	// Flags, defined by DefineFlags
+	baseFile *string
+	dryRun   *bool
+}
+
+// NewMergeCommand creates a new instance of MergeCommand
+func NewMergeCommand(tm task.ITaskManager, p Presenter) *MergeCommand {
+	return &MergeCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// DefineFlags defines the flags of the merge command
+func (c *MergeCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.baseFile = cmd.String("base", "", "Tasks file both copies started from, for a three-way merge")
+	c.dryRun = cmd.Bool("dry-run", false, "Show what would change without saving")
+}
+
+// Execute executes the merge command
+func (c *MergeCommand) Execute(args []string) error {
+	cmd := newFlagSet("merge")
+	c.DefineFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+	fileArgs := cmd.Args()
+
+	if len(fileArgs) == 0 {
+		return c.presenter.PrintError("%w", newUsageError("tasks file to merge is required"))
+	}
+
+	other, err := readTasks(fileArgs[0])
+	if err != nil {
+		return c.presenter.PrintError("error reading %s: %w", fileArgs[0], err)
+	}
+
+	var base []task.Task
+	if *c.baseFile != "" {
+		base, err = readTasks(*c.baseFile)
+		if err != nil {
+			return c.presenter.PrintError("error reading %s: %w", *c.baseFile, err)
+		}
+		if base == nil {
+			base = []task.Task{}
+		}
+	}
+
+	// A dry run merges into a copy of the tasks, so nothing is changed,
+	// saved or notified to hooks and to the daemon
+	target := c.tm
+	if *c.dryRun {
+		target = task.Snapshot(c.tm)
+	}
+	result := target.Merge(base, other)
+
+	for _, conflict := range result.Conflicts {
+		field := ""
+		if conflict.Field != "" {
+			field = fmt.Sprintf(" %s", conflict.Field)
+		}
+		c.presenter.PrintSuccess("Conflict on task %d%s (%s): %s", conflict.ID, field, conflict.Title, conflict.Resolution)
+	}
+
+	if *c.dryRun {
+		c.presenter.PrintSuccess("Dry run: %d task(s) would be added, %d updated, %d removed, %d conflict(s)",
+			result.Added, result.Updated, result.Removed, len(result.Conflicts))
+		return nil
+	}
+
+	if err := c.tm.SaveTasks(); err != nil {
+		return c.presenter.PrintError("error saving tasks: %w", err)
+	}
+
+	c.presenter.PrintSuccess("Merge finished: %d added, %d updated, %d removed, %d conflict(s)",
+		result.Added, result.Updated, result.Removed, len(result.Conflicts))
+	return nil
+}
+
+// readTasks reads a tasks file like the ones in the data directory
+func readTasks(fileName string) ([]task.Task, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []task.Task
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// Help returns the help message for the merge command
+func (c *MergeCommand) Help() string {
+	return `Merge the tasks saved by another copy of task-cli
+
+Usage:
+  task merge <file> [flags]
+
+Arguments:
+  <file>    A tasks.json file from another machine
+
+Flags:
+  -base string   The tasks.json both copies started from. With it the
+                 merge is three-way and a field changed on one side
+                 only always keeps that change
+  -dry-run       Show what would change without saving
+
+Tasks are matched by their UID, so tasks added on both sides are all
+kept, and the incoming ones whose ID is already in use get a new one.
+Fields changed on both sides take the most recently modified value and
+are reported as conflicts. Time entries and history are combined.`
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"flag"
+	"github.com/kubaliski/task-cli/internal/task"
+)
+
+type NextCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+
+	// Flags, defined by DefineFlags
+	where *string
+}
+
+// NewNextCommand creates a new instance of NextCommand
+func NewNextCommand(tm task.ITaskManager, p Presenter) *NextCommand {
+	return &NextCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// DefineFlags defines the flags of the next command
+func (c *NextCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.where = cmd.String("where", "", "Only consider the tasks matching a filter expression")
+}
+
+// Execute executes the next command
+func (c *NextCommand) Execute(args []string) error {
+	cmd := newFlagSet("next")
+	c.DefineFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	tasks, err := task.ListTasks(c.tm, task.ListOptions{Where: *c.where, ByUrgency: true})
+	if err != nil {
+		return c.presenter.PrintError("%w", err)
+	}
+
+	// The most urgent task that can be worked on
+	for _, t := range tasks {
+		if t.IsBlocked() || t.Priority == task.PrioritySomeday {
+			continue
+		}
+		return c.presenter.PrintTask(t)
+	}
+
+	c.presenter.PrintSuccess("No actionable tasks")
+	return nil
+}
+
+// Help returns the help message for the next command
+func (c *NextCommand) Help() string {
+	return `Show the most urgent task to work on
+
+Usage:
+  task next [flags]
+
+Flags:
+  -where string   Only consider the tasks matching a filter expression
+                  (see 'task help list')
+
+The task is the pending one with the highest urgency score, leaving out
+the blocked tasks (tagged #blocked) and the ones for someday. The score
+adds up weights for the priority, how close the due date is, the age of the
+task, the blocked tag and other tags, shown next to "Urgency". The weights
+can be changed in config.json, see the README.`
+}
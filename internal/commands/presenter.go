@@ -1,9 +1,15 @@
 package commands
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"io"
+	"math"
+	"os"
 	"strings"
-	"task-cli/internal/task"
+	"time"
 	"unicode/utf8"
 )
 
@@ -17,15 +23,64 @@ type TableColumn struct {
 // DefaultPresenter implement the default presenter
 type DefaultPresenter struct {
 	columns []TableColumn
+	out     io.Writer
+	format  string
 }
 
 // NewDefaultPresenter create a new instance of DefaultPresenter
 func NewDefaultPresenter() *DefaultPresenter {
-	p := &DefaultPresenter{}
+	return NewPresenter(Options{Color: ColorAuto})
+}
+
+// NewPresenter creates a presenter that follows the color and format
+// global options
+func NewPresenter(opts Options) *DefaultPresenter {
+	return NewPresenterTo(os.Stdout, opts)
+}
+
+// NewPresenterTo creates a presenter writing to w instead of the standard
+// output
+func NewPresenterTo(w io.Writer, opts Options) *DefaultPresenter {
+	p := &DefaultPresenter{out: w, format: opts.Format}
+	if !useColor(opts.Color, w) {
+		p.out = noColorWriter{w: w}
+	}
 	p.initializeColumns()
 	return p
 }
 
+// useColor tells whether the output written to w is colored. In auto mode
+// it is when w is a terminal and NO_COLOR is not set.
+func useColor(mode string, w io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// noColorWriter removes the colors of the output
+type noColorWriter struct {
+	w io.Writer
+}
+
+func (w noColorWriter) Write(b []byte) (int, error) {
+	if _, err := io.WriteString(w.w, stripANSI(string(b))); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
 // initializeColumns initialize the columns for the table
 func (p *DefaultPresenter) initializeColumns() {
 	p.columns = []TableColumn{
@@ -45,7 +100,7 @@ func (p *DefaultPresenter) initializeColumns() {
 		},
 		{
 			Header: "Priority",
-			Width:  7,
+			Width:  8,
 			Get: func(t task.Task) string {
 				priority := t.Priority.String()
 				if priority == "" {
@@ -57,6 +112,16 @@ func (p *DefaultPresenter) initializeColumns() {
 					"\033[0m")
 			},
 		},
+		{
+			Header: "Urgency",
+			Width:  7,
+			Get: func(t task.Task) string {
+				if t.Done || t.IsDeleted() {
+					return "---"
+				}
+				return fmt.Sprintf("%.1f", t.Urgency(time.Now()))
+			},
+		},
 		{
 			Header: "Title",
 			Width:  40,
@@ -141,8 +206,32 @@ func stripANSI(str string) string {
 	return result.String()
 }
 
-// PrintTaskTable implement the table format view
+// PrintTaskTable implement the table format view, unless --format asks
+// for another one
 func (p *DefaultPresenter) PrintTaskTable(tasks []task.Task) error {
+	switch p.format {
+	case FormatJSON:
+		return p.PrintJSON(tasks)
+	case FormatList:
+		return p.printTaskList(tasks)
+	}
+	return p.printTaskTable(tasks)
+}
+
+// PrintTaskList implement the list format view, unless --format asks for
+// another one
+func (p *DefaultPresenter) PrintTaskList(tasks []task.Task) error {
+	switch p.format {
+	case FormatJSON:
+		return p.PrintJSON(tasks)
+	case FormatTable:
+		return p.printTaskTable(tasks)
+	}
+	return p.printTaskList(tasks)
+}
+
+// printTaskTable prints the tasks in a table
+func (p *DefaultPresenter) printTaskTable(tasks []task.Task) error {
 	if len(tasks) == 0 {
 		return nil
 	}
@@ -154,25 +243,25 @@ func (p *DefaultPresenter) PrintTaskTable(tasks []task.Task) error {
 	}
 
 	// Print superior separator
-	fmt.Println(separator)
+	fmt.Fprintln(p.out, separator)
 
 	// Print Headers
 	for i, col := range p.columns {
 		if i == 0 {
-			fmt.Print("|")
+			fmt.Fprint(p.out, "|")
 		}
-		fmt.Printf(" %s |", centerText(col.Header, col.Width))
+		fmt.Fprintf(p.out, " %s |", centerText(col.Header, col.Width))
 	}
-	fmt.Println()
+	fmt.Fprintln(p.out)
 
 	// Print separator after headers
-	fmt.Println(separator)
+	fmt.Fprintln(p.out, separator)
 
 	// Print rows
 	for _, t := range tasks {
 		for i, col := range p.columns {
 			if i == 0 {
-				fmt.Print("|")
+				fmt.Fprint(p.out, "|")
 			}
 			value := col.Get(t)
 			// Make sure the value fits in the column
@@ -180,49 +269,64 @@ func (p *DefaultPresenter) PrintTaskTable(tasks []task.Task) error {
 			if utf8.RuneCountInString(cleanValue) > col.Width {
 				value = truncateString(cleanValue, col.Width)
 			}
-			fmt.Printf(" %s |", centerText(value, col.Width))
+			fmt.Fprintf(p.out, " %s |", centerText(value, col.Width))
 		}
-		fmt.Println()
+		fmt.Fprintln(p.out)
 	}
 
 	// Print inferior separator
-	fmt.Println(separator)
+	fmt.Fprintln(p.out, separator)
 
 	return nil
 }
 
-// PrintTaskList implement the list format view
-func (p *DefaultPresenter) PrintTaskList(tasks []task.Task) error {
+// printTaskList prints the tasks one after another in detail
+func (p *DefaultPresenter) printTaskList(tasks []task.Task) error {
 	for _, t := range tasks {
-		if err := p.PrintTask(t); err != nil {
+		if err := p.printTask(t); err != nil {
 			return err
 		}
-		fmt.Println(strings.Repeat("-", 50))
+		fmt.Fprintln(p.out, strings.Repeat("-", 50))
 	}
 	return nil
 }
 
 // PrintTask implement the task format view (detailed)
 func (p *DefaultPresenter) PrintTask(t task.Task) error {
+	if p.format == FormatJSON {
+		return p.PrintJSON(t)
+	}
+	return p.printTask(t)
+}
+
+// printTask prints a task in detail
+func (p *DefaultPresenter) printTask(t task.Task) error {
 	priorityStr := fmt.Sprintf("%s%s%s",
 		t.Priority.Color(),
 		t.Priority.String(),
 		"\033[0m")
 
-	fmt.Printf("\n%s Task #%d: %s - %s\n",
+	fmt.Fprintf(p.out, "\n%s Task #%d: %s - %s\n",
 		getStatusIcon(t),
 		t.ID,
 		priorityStr,
 		t.Title)
 
-	fmt.Printf("   Created: %s\n", t.CreatedAt.Format("2006-01-02 15:04:05"))
+	if t.UID != "" {
+		fmt.Fprintf(p.out, "   UID: %s\n", t.UID)
+	}
+	fmt.Fprintf(p.out, "   Created: %s\n", t.CreatedAt.Format("2006-01-02 15:04:05"))
+
+	if len(t.Tags) > 0 {
+		fmt.Fprintf(p.out, "   Tags: #%s\n", strings.Join(t.Tags, " #"))
+	}
 
 	if t.DueDate != nil {
 		dueStr := fmt.Sprintf("   Due: %s", task.FormatDateTime(t.DueDate))
 		if t.IsOverdue() {
 			dueStr = task.TimeStatusOverdue.Color() + dueStr + "\033[0m"
 		}
-		fmt.Println(dueStr)
+		fmt.Fprintln(p.out, dueStr)
 	}
 
 	if t.Reminder != nil {
@@ -230,19 +334,121 @@ func (p *DefaultPresenter) PrintTask(t task.Task) error {
 		if t.IsUpcoming() {
 			reminderStr = task.TimeStatusUpcoming.Color() + reminderStr + "\033[0m"
 		}
-		fmt.Println(reminderStr)
+		fmt.Fprintln(p.out, reminderStr)
+	}
+
+	if t.Estimate > 0 || len(t.TimeEntries) > 0 {
+		fmt.Fprintln(p.out, formatEffort(t))
+	}
+
+	if !t.Done && !t.IsDeleted() {
+		fmt.Fprintf(p.out, "   Urgency: %s\n", formatUrgency(t, time.Now()))
 	}
 
 	if t.Done {
-		fmt.Printf("   Completed: %s\n", t.CompletedAt.Format("2006-01-02 15:04:05"))
+		fmt.Fprintf(p.out, "   Completed: %s\n", t.CompletedAt.Format("2006-01-02 15:04:05"))
+		if t.CompletionNote != "" {
+			fmt.Fprintf(p.out, "   Note: %s\n", t.CompletionNote)
+		}
+	}
+
+	if t.DeletedAt != nil {
+		fmt.Fprintf(p.out, "   Deleted: %s\n", t.DeletedAt.Format("2006-01-02 15:04:05"))
 	}
 
 	return nil
 }
 
+// PrintHistory implement the history view of a task, oldest changes first
+func (p *DefaultPresenter) PrintHistory(t task.Task) error {
+	if p.format == FormatJSON {
+		return p.PrintJSON(t.History)
+	}
+
+	fmt.Fprintf(p.out, "\nHistory of task #%d: %s\n", t.ID, t.Title)
+
+	if len(t.History) == 0 {
+		fmt.Fprintln(p.out, "   No recorded changes")
+		return nil
+	}
+
+	for _, h := range t.History {
+		user := h.User
+		if user == "" {
+			user = "unknown"
+		}
+
+		description := h.Action
+		if h.Field != "" {
+			description = fmt.Sprintf("%s: %s → %s", h.Field, historyValue(h.OldValue), historyValue(h.NewValue))
+		}
+		if h.Note != "" {
+			description += fmt.Sprintf(" (%s)", h.Note)
+		}
+
+		fmt.Fprintf(p.out, "   %s  %-10s %s\n", h.At.Format("2006-01-02 15:04:05"), user, description)
+	}
+
+	return nil
+}
+
+// historyValue returns the value to show in the history for a field
+func historyValue(value string) string {
+	if value == "" {
+		return "---"
+	}
+	return value
+}
+
+// formatUrgency returns the urgency score of a task with its parts
+func formatUrgency(t task.Task, now time.Time) string {
+	var parts []string
+	for _, term := range t.UrgencyTerms(now) {
+		// Parts too small to show, like the age of a new task, are left out
+		if math.Abs(term.Value) >= 0.05 {
+			parts = append(parts, fmt.Sprintf("%s %+.1f", term.Name, term.Value))
+		}
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("%.1f", t.Urgency(now))
+	}
+	return fmt.Sprintf("%.1f (%s)", t.Urgency(now), strings.Join(parts, ", "))
+}
+
+// formatEffort returns the estimated vs tracked time line of a task
+func formatEffort(t task.Task) string {
+	tracked := t.TrackedTime()
+	line := fmt.Sprintf("   Tracked: %s", task.FormatDuration(tracked))
+
+	if t.Estimate > 0 {
+		percent := int(tracked * 100 / t.Estimate)
+		line += fmt.Sprintf(" of %s estimated (%d%%)", task.FormatDuration(t.Estimate), percent)
+		if tracked > t.Estimate {
+			line = task.TimeStatusOverdue.Color() + line + "\033[0m"
+		}
+	}
+
+	if t.IsTimerRunning() {
+		start := t.TimeEntries[len(t.TimeEntries)-1].Start
+		line += fmt.Sprintf("\n   Timer running since %s", start.Format("2006-01-02 15:04"))
+	}
+
+	return line
+}
+
+// PrintJSON print a value as indented JSON
+func (p *DefaultPresenter) PrintJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(p.out, string(data))
+	return nil
+}
+
 // PrintSuccess print a success message
 func (p *DefaultPresenter) PrintSuccess(format string, a ...interface{}) {
-	fmt.Printf(format+"\n", a...)
+	fmt.Fprintf(p.out, format+"\n", a...)
 }
 
 // PrintError print an error message
@@ -250,6 +456,24 @@ func (p *DefaultPresenter) PrintError(format string, a ...interface{}) error {
 	return fmt.Errorf(format, a...)
 }
 
+// Confirm asks a yes/no question on the terminal, anything but "y" or "yes" is a no
+func (p *DefaultPresenter) Confirm(format string, a ...interface{}) bool {
+	fmt.Fprintf(p.out, format+" [y/N]: ", a...)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(p.out)
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
 // getStatusString returns formatted status string
 func getStatusString(t task.Task) string {
 	if t.Done {
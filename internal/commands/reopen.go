@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"flag"
+	"github.com/kubaliski/task-cli/internal/task"
+)
+
+type ReopenCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+}
+
+// NewReopenCommand creates a new instance of ReopenCommand
+func NewReopenCommand(tm task.ITaskManager, p Presenter) *ReopenCommand {
+	return &ReopenCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// DefineFlags defines the flags of the reopen command
+func (c *ReopenCommand) DefineFlags(cmd *flag.FlagSet) {
+	addStatusFlags(cmd, c.change())
+}
+
+// Execute executes the reopen command
+func (c *ReopenCommand) Execute(args []string) error {
+	return runStatusChange(c.tm, c.presenter, args, c.change())
+}
+
+// change describes what the reopen command does to the tasks
+func (c *ReopenCommand) change() statusChange {
+	return statusChange{
+		name:     "reopen",
+		noteHelp: "Reason for reopening",
+		done:     false,
+		action:   "reopened",
+		skipped:  "is not completed",
+		failure:  "error reopening task",
+		apply:    c.tm.ReopenTask,
+	}
+}
+
+// Help returns the help message for the reopen command
+func (c *ReopenCommand) Help() string {
+	return `Mark completed tasks as pending again
+
+Usage:
+  task reopen <ids> [flags]
+  task reopen -where <filter> [flags]
+
+Arguments:
+  <ids>    Task IDs and ranges, e.g. 3 or 3,5,8-12
+
+Flags:
+  -note string    Reason for reopening, kept in the task history
+  -where string   Reopen the tasks matching a filter expression
+  -dry-run        Show the affected tasks without changing them
+  -yes            Don't ask for confirmation`
+}
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"strings"
+	"time"
+)
+
+// reportBarWidth is the width of the longest bar in the text report
+const reportBarWidth = 40
+
+type ReportCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+
+	// Flags, defined by DefineFlags
+	fromFlag *string
+	toFlag   *string
+	interval *string
+	format   *string
+}
+
+// NewReportCommand creates a new instance of ReportCommand
+func NewReportCommand(tm task.ITaskManager, p Presenter) *ReportCommand {
+	return &ReportCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// DefineFlags defines the flags of the report command
+func (c *ReportCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.fromFlag = cmd.String("from", "", "First day of the report (default: 30 days ago)")
+	c.toFlag = cmd.String("to", "", "Last day of the report (default: today)")
+	c.interval = cmd.String("by", task.IntervalDay, "Group completed tasks by day or week")
+	c.format = cmd.String("format", "text", "Output format: text or json")
+}
+
+// Execute executes the report command
+func (c *ReportCommand) Execute(args []string) error {
+	cmd := newFlagSet("report")
+	c.DefineFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	to := time.Now()
+	if *c.toFlag != "" {
+		t, err := task.ParseDateTime(*c.toFlag)
+		if err != nil {
+			return c.presenter.PrintError("invalid end date: %w", err)
+		}
+		to = t
+	}
+
+	from := to.AddDate(0, 0, -30)
+	if *c.fromFlag != "" {
+		t, err := task.ParseDateTime(*c.fromFlag)
+		if err != nil {
+			return c.presenter.PrintError("invalid start date: %w", err)
+		}
+		from = t
+	}
+
+	report, err := task.BuildReport(allTasks(c.tm), from, to, *c.interval)
+	if err != nil {
+		return c.presenter.PrintError("error building report: %w", err)
+	}
+
+	switch *c.format {
+	case "json":
+		return c.presenter.PrintJSON(report)
+	case "text":
+		c.presenter.PrintSuccess(formatReport(report))
+		return nil
+	default:
+		return c.presenter.PrintError("%w", newUsageError("unknown format: %s", *c.format))
+	}
+}
+
+// formatReport renders the report as text with bar charts
+func formatReport(r task.Report) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Report from %s to %s\n\n", r.From.Format("2006-01-02"), r.To.Format("2006-01-02"))
+
+	fmt.Fprintf(&sb, "Completed per %s:\n", r.Interval)
+	maxCompleted := 0
+	for _, p := range r.Periods {
+		maxCompleted = max(maxCompleted, p.Completed)
+	}
+	for _, p := range r.Periods {
+		fmt.Fprintf(&sb, "  %s  %s %d\n", p.Start.Format("2006-01-02"), bar(p.Completed, maxCompleted), p.Completed)
+	}
+
+	sb.WriteString("\nBurndown (open tasks at the end of each day):\n")
+	maxOpen := 0
+	for _, b := range r.Burndown {
+		maxOpen = max(maxOpen, b.Open)
+	}
+	for _, b := range r.Burndown {
+		fmt.Fprintf(&sb, "  %s  %s %d\n", b.Date.Format("2006-01-02"), bar(b.Open, maxOpen), b.Open)
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// bar returns a horizontal bar proportional to value/maxValue
+func bar(value, maxValue int) string {
+	if maxValue == 0 {
+		return ""
+	}
+	width := value * reportBarWidth / maxValue
+	if value > 0 && width == 0 {
+		width = 1
+	}
+	return strings.Repeat("█", width)
+}
+
+// Help returns the help message for the report command
+func (c *ReportCommand) Help() string {
+	return `Show a productivity report for a date range
+
+Usage:
+  task report [flags]
+
+Flags:
+  -from string     First day of the report, YYYY-MM-DD (default: 30 days ago)
+  -to string       Last day of the report, YYYY-MM-DD (default: today)
+  -by string       Group completed tasks by day or week (default: day)
+  -format string   Output format: text or json (default: text)
+
+Shows the tasks completed per day or week and a daily burndown
+of the open tasks. Days are local days and a report covers at most
+3660 days (about ten years).`
+}
@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/backup"
+	"github.com/kubaliski/task-cli/internal/task"
+	"os"
+	"strings"
+)
+
+type RestoreCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+
+	// Flags, defined by DefineFlags
+	dryRun *bool
+	yes    *bool
+}
+
+// NewRestoreCommand creates a new instance of RestoreCommand
+func NewRestoreCommand(tm task.ITaskManager, p Presenter) *RestoreCommand {
+	return &RestoreCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// DefineFlags defines the flags of the restore command
+func (c *RestoreCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.dryRun = cmd.Bool("dry-run", false, "Show the changes of a backup restore without applying them")
+	c.yes = cmd.Bool("yes", false, "Don't ask for confirmation")
+}
+
+// Execute executes the restore command
+func (c *RestoreCommand) Execute(args []string) error {
+	cmd := newFlagSet("restore")
+	c.DefineFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+	positional := cmd.Args()
+
+	if len(positional) == 0 {
+		return c.presenter.PrintError("%w", newUsageError("task ID or backup file is required"))
+	}
+
+	// Task IDs restore from the trash, anything else is a backup file
+	ids, err := task.ParseIDs(strings.Join(positional, ","))
+	if err != nil {
+		return c.restoreBackup(positional[0], *c.dryRun, *c.yes)
+	}
+	return c.restoreFromTrash(ids)
+}
+
+// restoreFromTrash moves the tasks back from the trash
+func (c *RestoreCommand) restoreFromTrash(ids []int) error {
+	for _, id := range ids {
+		if err := c.tm.RestoreTask(id); err != nil {
+			return c.presenter.PrintError("error restoring task: %w", err)
+		}
+	}
+
+	if err := c.tm.SaveTasks(); err != nil {
+		return c.presenter.PrintError("error saving changes: %w", err)
+	}
+
+	if len(ids) == 1 {
+		c.presenter.PrintSuccess("Task %d restored from trash", ids[0])
+	} else {
+		c.presenter.PrintSuccess("%d tasks restored from trash", len(ids))
+	}
+	return nil
+}
+
+// restoreBackup replaces the data with the content of a backup file
+func (c *RestoreCommand) restoreBackup(fileName string, dryRun, yes bool) error {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return c.presenter.PrintError("error opening backup: %w", err)
+	}
+	defer f.Close()
+
+	archive, err := backup.Read(f)
+	if err != nil {
+		return c.presenter.PrintError("invalid backup: %w", err)
+	}
+
+	dataDir, err := task.DataDir()
+	if err != nil {
+		return c.presenter.PrintError("error finding data directory: %w", err)
+	}
+
+	diffs, err := archive.Diff(dataDir)
+	if err != nil {
+		return c.presenter.PrintError("error comparing backup: %w", err)
+	}
+
+	c.presenter.PrintSuccess("Backup from %s:\n%s",
+		archive.Manifest.CreatedAt.Format("2006-01-02 15:04:05"),
+		formatBackupDiff(diffs))
+
+	if dryRun {
+		c.presenter.PrintSuccess("Dry run: nothing was restored")
+		return nil
+	}
+
+	if !yes && !c.presenter.Confirm("Replace the current data with the backup?") {
+		c.presenter.PrintSuccess("Aborted, nothing was restored")
+		return nil
+	}
+
+	if err := archive.Restore(dataDir); err != nil {
+		return c.presenter.PrintError("error restoring backup: %w", err)
+	}
+
+	// Reload the files so a running daemon doesn't keep the old data
+	if err := c.tm.LoadTasks(); err != nil {
+		return c.presenter.PrintError("error loading restored tasks: %w", err)
+	}
+
+	c.presenter.PrintSuccess("Backup restored")
+	return nil
+}
+
+// formatBackupDiff describes the changes of a restore, one file per line
+func formatBackupDiff(diffs []backup.FileDiff) string {
+	var sb strings.Builder
+	for _, d := range diffs {
+		fmt.Fprintf(&sb, "  %-14s %s", d.Name, d.Status)
+		if d.IsTasks && d.Status != "unchanged" {
+			fmt.Fprintf(&sb, " (%d added, %d removed, %d changed tasks)", d.Added, d.Removed, d.Changed)
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// Help returns the help message for the restore command
+func (c *RestoreCommand) Help() string {
+	return `Restore tasks from the trash or data from a backup
+
+Usage:
+  task restore <ids>
+  task restore <file> [flags]
+
+Arguments:
+  <ids>     IDs of deleted tasks to restore, e.g. 3 or 3,5,8-12
+  <file>    Backup file created with 'task backup'
+
+Flags:
+  -dry-run   Show the changes of a backup restore without applying them
+  -yes       Don't ask for confirmation
+
+Backups are validated against their checksums and a summary of the
+changes is shown before the current data is replaced. When the restore
+fails partway the current data is put back.`
+}
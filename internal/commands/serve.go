@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"github.com/kubaliski/task-cli/internal/server"
+	"github.com/kubaliski/task-cli/internal/task"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+)
+
+type ServeCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+
+	// Flags, defined by DefineFlags
+	addr *string
+}
+
+// NewServeCommand creates a new instance of ServeCommand
+func NewServeCommand(tm task.ITaskManager, p Presenter) *ServeCommand {
+	return &ServeCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// DefineFlags defines the flags of the serve command
+func (c *ServeCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.addr = cmd.String("addr", "127.0.0.1:8080", "Address to listen on, only this machine by default")
+}
+
+// Execute executes the serve command
+func (c *ServeCommand) Execute(args []string) error {
+	cmd := newFlagSet("serve")
+	c.DefineFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	srv := &http.Server{
+		Addr:              *c.addr,
+		Handler:           server.New(c.tm),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Stop cleanly on Ctrl+C so no request is cut in the middle of a save
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go watchAging(ctx, c.tm)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	c.presenter.PrintSuccess("Serving tasks on %s (press Ctrl+C to stop)", *c.addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return c.presenter.PrintError("error serving tasks: %w", err)
+	}
+	return nil
+}
+
+// Help returns the help message for the serve command
+func (c *ServeCommand) Help() string {
+	return `Serve the tasks through a JSON REST API
+
+Usage:
+  task serve [flags]
+
+Flags:
+  -addr string   Address to listen on (default: 127.0.0.1:8080). The API
+                 has no authentication, use :8080 to accept connections
+                 from other machines only on a trusted network
+
+Endpoints:
+  GET    /tasks                  List tasks, the query parameters are the
+                                 list flags: where, due, priority, by-due,
+                                 urgency, all and archived
+  POST   /tasks                  Create a task: {"title", "priority", "due",
+                                 "reminder", "tags", "estimate"}
+  GET    /tasks/{id}             Get a task
+  PATCH  /tasks/{id}             Update a task: {"title", "priority",
+                                 "done", "tags", "estimate"}
+  DELETE /tasks/{id}             Move a task to the trash
+  PUT    /tasks/{id}/due         Set the due date: {"due": "2024-01-10 15:00"}
+  DELETE /tasks/{id}/due         Remove the due date
+  PUT    /tasks/{id}/reminder    Set the reminder: {"reminder": "..."}
+  DELETE /tasks/{id}/reminder    Remove the reminder
+
+Dates use the command line formats or RFC 3339. Request bodies are limited
+to 1 MB. Errors are returned as {"error": "..."} with status 400 for invalid
+input, 404 for unknown tasks, 409 when the state of the task doesn't allow
+the change, 413 for bodies too large or 500 when the tasks can't be saved.
+Invalid input never changes a task.`
+}
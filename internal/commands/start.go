@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"github.com/kubaliski/task-cli/internal/task"
+)
+
+type StartCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+}
+
+// NewStartCommand creates a new instance of StartCommand
+func NewStartCommand(tm task.ITaskManager, p Presenter) *StartCommand {
+	return &StartCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// Execute executes the start command
+func (c *StartCommand) Execute(args []string) error {
+	cmd := newFlagSet("start")
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	if len(cmd.Args()) == 0 {
+		return c.presenter.PrintError("%w", newUsageError("task ID is required"))
+	}
+
+	id, err := parseID(cmd.Args()[0])
+	if err != nil {
+		return c.presenter.PrintError("%w", err)
+	}
+
+	if err := c.tm.StartTimer(id); err != nil {
+		return c.presenter.PrintError("error starting timer: %w", err)
+	}
+
+	if err := c.tm.SaveTasks(); err != nil {
+		return c.presenter.PrintError("error saving changes: %w", err)
+	}
+
+	c.presenter.PrintSuccess("Timer started on task %d", id)
+	return nil
+}
+
+// Help returns the help message for the start command
+func (c *StartCommand) Help() string {
+	return `Start tracking time on a task
+
+Usage:
+  task start <id>
+
+Arguments:
+  <id>    The ID of the task to work on
+
+Only one timer can run at a time, stop it with 'task stop'.`
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"strings"
+)
+
+type StatsCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+
+	// Flags, defined by DefineFlags
+	format *string
+}
+
+// NewStatsCommand creates a new instance of StatsCommand
+func NewStatsCommand(tm task.ITaskManager, p Presenter) *StatsCommand {
+	return &StatsCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// DefineFlags defines the flags of the stats command
+func (c *StatsCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.format = cmd.String("format", "text", "Output format: text or json")
+}
+
+// Execute executes the stats command
+func (c *StatsCommand) Execute(args []string) error {
+	cmd := newFlagSet("stats")
+	c.DefineFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	stats := task.ComputeStats(allTasks(c.tm))
+
+	switch *c.format {
+	case "json":
+		return c.presenter.PrintJSON(stats)
+	case "text":
+		c.presenter.PrintSuccess(formatStats(stats))
+		return nil
+	default:
+		return c.presenter.PrintError("%w", newUsageError("unknown format: %s", *c.format))
+	}
+}
+
+// allTasks returns the active and archived tasks, the archive holds most
+// of the completion history
+func allTasks(tm task.ITaskManager) []task.Task {
+	return append(tm.GetTasksSorted(false, false), tm.GetArchivedTasks()...)
+}
+
+// formatStats renders the statistics as text
+func formatStats(s task.Stats) string {
+	var sb strings.Builder
+
+	sb.WriteString("Task statistics\n\n")
+	fmt.Fprintf(&sb, "  Total:      %d\n", s.Total)
+	fmt.Fprintf(&sb, "  Open:       %d\n", s.Open)
+	fmt.Fprintf(&sb, "  Completed:  %d\n", s.Completed)
+
+	sb.WriteString("\nOpen by priority:\n")
+	for i := len(task.Priorities) - 1; i >= 0; i-- {
+		p := task.Priorities[i]
+		// The levels besides low, medium and high only when used
+		if s.OpenByPriority[p.String()] == 0 && (p < task.PriorityLow || p > task.PriorityHigh) {
+			continue
+		}
+		fmt.Fprintf(&sb, "  %s%-8s\033[0m %d\n", p.Color(), p.String(), s.OpenByPriority[p.String()])
+	}
+
+	sb.WriteString("\nOpen by time status:\n")
+	for _, ts := range []task.TimeStatus{task.TimeStatusOverdue, task.TimeStatusDueSoon, task.TimeStatusUpcoming, task.TimeStatusNormal} {
+		fmt.Fprintf(&sb, "  %s%-8s\033[0m %d\n", ts.Color(), ts.String(), s.OpenByTimeStatus[ts.String()])
+	}
+
+	sb.WriteString("\nCompletion:\n")
+	if s.CompletedWithDue > 0 {
+		fmt.Fprintf(&sb, "  On time:    %d of %d with due date (%.0f%%)\n", s.CompletedOnTime, s.CompletedWithDue, s.OnTimeRate*100)
+		fmt.Fprintf(&sb, "  Late:       %d\n", s.CompletedLate)
+	} else {
+		sb.WriteString("  On time:    no completed tasks with due date\n")
+	}
+	if s.Completed > 0 {
+		fmt.Fprintf(&sb, "  Lead time:  %s on average\n", task.FormatDuration(s.AverageLeadTime))
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// Help returns the help message for the stats command
+func (c *StatsCommand) Help() string {
+	return `Show task statistics
+
+Usage:
+  task stats [flags]
+
+Flags:
+  -format string   Output format: text or json (default: text)
+
+Shows open tasks by priority and time status, the on-time completion
+rate and the average lead time from creation to completion.`
+}
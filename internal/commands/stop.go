@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"github.com/kubaliski/task-cli/internal/task"
+)
+
+type StopCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+}
+
+// NewStopCommand creates a new instance of StopCommand
+func NewStopCommand(tm task.ITaskManager, p Presenter) *StopCommand {
+	return &StopCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// Execute executes the stop command
+func (c *StopCommand) Execute(args []string) error {
+	cmd := newFlagSet("stop")
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	t, err := c.tm.StopTimer()
+	if err != nil {
+		return c.presenter.PrintError("error stopping timer: %w", err)
+	}
+
+	if err := c.tm.SaveTasks(); err != nil {
+		return c.presenter.PrintError("error saving changes: %w", err)
+	}
+
+	last := t.TimeEntries[len(t.TimeEntries)-1]
+	c.presenter.PrintSuccess("Timer stopped on task %d after %s (total: %s)",
+		t.ID,
+		task.FormatDuration(last.Duration()),
+		task.FormatDuration(t.TrackedTime()))
+	return nil
+}
+
+// Help returns the help message for the stop command
+func (c *StopCommand) Help() string {
+	return `Stop the running timer
+
+Usage:
+  task stop`
+}
@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"github.com/kubaliski/task-cli/internal/gitsync"
+	"github.com/kubaliski/task-cli/internal/task"
+	"strings"
+)
+
+type SyncCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+}
+
+// NewSyncCommand creates a new instance of SyncCommand
+func NewSyncCommand(tm task.ITaskManager, p Presenter) *SyncCommand {
+	return &SyncCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// Execute executes the sync command
+func (c *SyncCommand) Execute(args []string) error {
+	if len(args) > 0 && args[0] == "init" {
+		return c.init(args[1:])
+	}
+
+	cmd := newFlagSet("sync")
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	repo, err := syncRepo()
+	if err != nil {
+		return c.presenter.PrintError("error finding data directory: %w", err)
+	}
+
+	result, err := repo.Sync()
+	if err != nil {
+		return c.presenter.PrintError("error syncing tasks: %w", err)
+	}
+
+	// Reload the files so a running daemon doesn't keep the old data
+	if result.Pulled {
+		if err := c.tm.LoadTasks(); err != nil {
+			return c.presenter.PrintError("error loading synced tasks: %w", err)
+		}
+	}
+
+	for _, conflict := range result.Conflicts {
+		c.presenter.PrintSuccess("Conflict on task %d (%s): %s", conflict.ID, conflict.Title, conflict.Resolution)
+	}
+
+	switch {
+	case result.Merged:
+		c.presenter.PrintSuccess("Merged remote changes with %d conflict(s)", len(result.Conflicts))
+	case result.Pulled:
+		c.presenter.PrintSuccess("Pulled remote changes")
+	}
+	if result.Pushed {
+		c.presenter.PrintSuccess("Pushed local changes")
+	}
+	if !result.Pulled && !result.Pushed {
+		c.presenter.PrintSuccess("Already up to date")
+	}
+	return nil
+}
+
+// init sets up the data directory as a git repository
+func (c *SyncCommand) init(args []string) error {
+	cmd := newFlagSet("sync init")
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	if len(cmd.Args()) == 0 {
+		return c.presenter.PrintError("%w", newUsageError("remote repository is required"))
+	}
+	remote := cmd.Args()[0]
+
+	repo, err := syncRepo()
+	if err != nil {
+		return c.presenter.PrintError("error finding data directory: %w", err)
+	}
+
+	if err := repo.Init(remote); err != nil {
+		return c.presenter.PrintError("error initializing sync: %w", err)
+	}
+
+	c.presenter.PrintSuccess("Sync initialized with remote %s", remote)
+	return nil
+}
+
+// syncRepo returns the git repository of the data directory
+func syncRepo() (*gitsync.Repo, error) {
+	dataDir, err := task.DataDir()
+	if err != nil {
+		return nil, err
+	}
+	return gitsync.Open(dataDir), nil
+}
+
+// commitChanges records the changes made by a command when sync is set up
+func commitChanges(cmdName string, args []string) error {
+	repo, err := syncRepo()
+	if err != nil || !repo.Enabled() {
+		return nil
+	}
+
+	message := strings.TrimSpace("task " + cmdName + " " + strings.Join(args, " "))
+	_, err = repo.Commit(message)
+	return err
+}
+
+// Help returns the help message for the sync command
+func (c *SyncCommand) Help() string {
+	return `Synchronize the tasks with a git remote
+
+Usage:
+  task sync init <remote>
+  task sync
+
+Subcommands:
+  init <remote>   Keep the data directory in a git repository that
+                  syncs with the given remote (a URL or a path)
+
+Without a subcommand, local changes are pulled and merged with the
+remote ones, then pushed. Once initialized, every change to the tasks
+is committed automatically.
+
+Concurrent edits are merged task by task and field by field, see
+'task help merge'. Conflicts are reported.`
+}
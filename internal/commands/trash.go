@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"flag"
+	"github.com/kubaliski/task-cli/internal/task"
+	"time"
+)
+
+type TrashCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+
+	// Flags of trash list and trash empty
+	format    *string
+	olderThan *string
+}
+
+// NewTrashCommand creates a new instance of TrashCommand
+func NewTrashCommand(tm task.ITaskManager, p Presenter) *TrashCommand {
+	return &TrashCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// DefineFlags defines the flags of the trash subcommands
+func (c *TrashCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.defineListFlags(cmd)
+	c.defineEmptyFlags(cmd)
+}
+
+// defineListFlags defines the flags of trash list
+func (c *TrashCommand) defineListFlags(cmd *flag.FlagSet) {
+	c.format = cmd.String("format", "table", "Output format: table or list")
+}
+
+// defineEmptyFlags defines the flags of trash empty
+func (c *TrashCommand) defineEmptyFlags(cmd *flag.FlagSet) {
+	c.olderThan = cmd.String("older-than", "", "Only remove tasks deleted before this age (e.g. 30d, 12h)")
+}
+
+// Execute executes the trash command
+func (c *TrashCommand) Execute(args []string) error {
+	if len(args) == 0 {
+		return c.list(args)
+	}
+
+	switch args[0] {
+	case "list":
+		return c.list(args[1:])
+	case "empty":
+		return c.empty(args[1:])
+	default:
+		return c.presenter.PrintError("%w", newUsageError("unknown trash subcommand: %s", args[0]))
+	}
+}
+
+// list shows the tasks in the trash
+func (c *TrashCommand) list(args []string) error {
+	cmd := newFlagSet("trash list")
+	c.defineListFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	tasks := c.tm.GetTrashedTasks()
+	if len(tasks) == 0 {
+		c.presenter.PrintSuccess("Trash is empty")
+		return nil
+	}
+
+	if *c.format == "list" {
+		return c.presenter.PrintTaskList(tasks)
+	}
+	return c.presenter.PrintTaskTable(tasks)
+}
+
+// empty permanently removes the tasks in the trash
+func (c *TrashCommand) empty(args []string) error {
+	cmd := newFlagSet("trash empty")
+	c.defineEmptyFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	var age time.Duration
+	if *c.olderThan != "" {
+		d, err := parseAge(*c.olderThan)
+		if err != nil {
+			return c.presenter.PrintError("invalid age: %w", err)
+		}
+		age = d
+	}
+
+	removed := c.tm.EmptyTrash(age)
+
+	if err := c.tm.SaveTasks(); err != nil {
+		return c.presenter.PrintError("error saving changes: %w", err)
+	}
+
+	c.presenter.PrintSuccess("%d task(s) permanently removed from trash", removed)
+	return nil
+}
+
+// Help returns the help message for the trash command
+func (c *TrashCommand) Help() string {
+	return `Manage deleted tasks
+
+Usage:
+  task trash list [flags]
+  task trash empty [flags]
+
+Subcommands:
+  list               Show the tasks in the trash (default)
+  empty              Permanently remove tasks from the trash
+
+Flags:
+  -format string       (list) Output format: table or list (default: table)
+  -older-than string   (empty) Only remove tasks deleted before this age,
+                       greater than zero, e.g. 30d, 2w, 12h
+                       (default: remove everything)`
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"github.com/kubaliski/task-cli/internal/task"
+)
+
+type UnarchiveCommand struct {
+	tm        task.ITaskManager
+	presenter Presenter
+}
+
+// NewUnarchiveCommand creates a new instance of UnarchiveCommand
+func NewUnarchiveCommand(tm task.ITaskManager, p Presenter) *UnarchiveCommand {
+	return &UnarchiveCommand{
+		tm:        tm,
+		presenter: p,
+	}
+}
+
+// Execute executes the unarchive command
+func (c *UnarchiveCommand) Execute(args []string) error {
+	cmd := newFlagSet("unarchive")
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
+	}
+
+	if len(cmd.Args()) == 0 {
+		return c.presenter.PrintError("%w", newUsageError("task ID is required"))
+	}
+
+	id, err := parseID(cmd.Args()[0])
+	if err != nil {
+		return c.presenter.PrintError("%w", err)
+	}
+
+	if err := c.tm.UnarchiveTask(id); err != nil {
+		return c.presenter.PrintError("error unarchiving task: %w", err)
+	}
+
+	if err := c.tm.SaveTasks(); err != nil {
+		return c.presenter.PrintError("error saving changes: %w", err)
+	}
+
+	c.presenter.PrintSuccess("Task %d moved back from the archive", id)
+	return nil
+}
+
+// Help returns the help message for the unarchive command
+func (c *UnarchiveCommand) Help() string {
+	return `Move a task back from the archive
+
+Usage:
+  task unarchive <id>
+
+Arguments:
+  <id>    The ID of the archived task`
+}
@@ -2,13 +2,25 @@ package commands
 
 import (
 	"flag"
-	"strconv"
-	"task-cli/internal/task"
+	"github.com/kubaliski/task-cli/internal/task"
+	"strings"
 )
 
 type UpdateCommand struct {
 	tm        task.ITaskManager
 	presenter Presenter
+
+	// Flags, defined by DefineFlags
+	title          *string
+	done           *bool
+	priorityFlag   *string
+	tags           *string
+	estimate       *string
+	dueDate        *string
+	reminder       *string
+	removeDue      *bool
+	removeReminder *bool
+	bulk           *bulkFlags
 }
 
 // NewUpdateCommand creates a new instance of UpdateCommand
@@ -19,139 +31,169 @@ func NewUpdateCommand(tm task.ITaskManager, p Presenter) *UpdateCommand {
 	}
 }
 
+// DefineFlags defines the flags of the update command
+func (c *UpdateCommand) DefineFlags(cmd *flag.FlagSet) {
+	c.title = cmd.String("title", "", "New task title")
+	c.done = cmd.Bool("done", false, "Mark task as done")
+	c.priorityFlag = cmd.String("priority", "", "Task priority (none, someday, low, medium, high, urgent)")
+	c.tags = cmd.String("tags", "", "Replace the task tags (comma separated)")
+	c.estimate = cmd.String("estimate", "", "Estimated effort (e.g. 2h, 0 to remove)")
+	c.dueDate = cmd.String("due", "", "Due date (format: YYYY-MM-DD HH:MM)")
+	c.reminder = cmd.String("reminder", "", "Reminder time (format: YYYY-MM-DD HH:MM)")
+	c.removeDue = cmd.Bool("remove-due", false, "Remove due date")
+	c.removeReminder = cmd.Bool("remove-reminder", false, "Remove reminder")
+	c.bulk = addBulkFlags(cmd)
+}
+
 // Execute executes the update command
 func (c *UpdateCommand) Execute(args []string) error {
-	if len(args) < 1 {
-		return c.presenter.PrintError("task ID is required")
+	cmd := newFlagSet("update")
+	c.DefineFlags(cmd)
+
+	if err := parseArgs(cmd, args); err != nil {
+		return c.presenter.PrintError("error parsing arguments: %w", err)
 	}
+	idArgs := cmd.Args()
 
-	// Parse task ID before parsing flags
-	id, err := strconv.Atoi(args[0])
+	changes, err := c.parseChanges(cmd, *c.title, *c.done, *c.priorityFlag, *c.tags, *c.estimate, *c.dueDate, *c.reminder, *c.removeDue, *c.removeReminder)
 	if err != nil {
-		return c.presenter.PrintError("invalid task ID: %v", err)
+		return err
 	}
 
-	cmd := flag.NewFlagSet("update", flag.ExitOnError)
-	title := cmd.String("title", "", "New task title")
-	done := cmd.Bool("done", false, "Mark task as done")
-	priorityFlag := cmd.String("priority", "", "Task priority (low, medium, high)")
-	dueDate := cmd.String("due", "", "Due date (format: YYYY-MM-DD HH:MM)")
-	reminder := cmd.String("reminder", "", "Reminder time (format: YYYY-MM-DD HH:MM)")
-	removeDue := cmd.Bool("remove-due", false, "Remove due date")
-	removeReminder := cmd.Bool("remove-reminder", false, "Remove reminder")
-
-	if err := cmd.Parse(args[1:]); err != nil {
-		return c.presenter.PrintError("error parsing arguments: %v", err)
+	targets, err := resolveTargets(c.tm, c.presenter, idArgs, *c.bulk.where)
+	if err != nil {
+		return err
 	}
 
-	// Get current task
-	currentTask, err := c.tm.GetTaskByID(id)
-	if err != nil {
-		return c.presenter.PrintError("error getting task: %v", err)
+	if changes.Title != "" && len(targets) > 1 {
+		return c.presenter.PrintError("%w", newUsageError("-title can only be used with a single task"))
 	}
 
-	// Update basic fields
-	if err := c.updateBasicFields(id, currentTask, *title, *done, *priorityFlag); err != nil {
-		return err
+	// Check the changes against every task before asking for confirmation,
+	// the task manager checks them again when applying them
+	for _, t := range targets {
+		if err := changes.Validate(t); err != nil {
+			return c.presenter.PrintError("error updating task %d: %w", t.ID, err)
+		}
 	}
 
-	// Manage due date updates
-	if err := c.updateDueDate(id, *dueDate, *removeDue); err != nil {
+	ok, err := confirmBulk(c.presenter, "updated", targets, c.bulk)
+	if err != nil || !ok {
 		return err
 	}
 
-	// Manage reminder updates
-	if err := c.updateReminder(id, *reminder, *removeReminder); err != nil {
-		return err
+	// All the tasks change at once, or none does
+	ids := make([]int, len(targets))
+	for i, t := range targets {
+		ids[i] = t.ID
+	}
+	if _, err := c.tm.UpdateTasks(ids, *changes); err != nil {
+		return c.presenter.PrintError("error updating tasks: %w", err)
 	}
 
 	// Save Changes
 	if err := c.tm.SaveTasks(); err != nil {
-		return c.presenter.PrintError("error saving changes: %v", err)
+		return c.presenter.PrintError("error saving changes: %w", err)
 	}
 
-	c.presenter.PrintSuccess("Task %d updated successfully", id)
+	if len(targets) == 1 {
+		c.presenter.PrintSuccess("Task %d updated successfully", targets[0].ID)
+	} else {
+		c.presenter.PrintSuccess("%d tasks updated successfully", len(targets))
+	}
 	return nil
 }
 
-// updateBasicFields updates the basic fields of a task
-func (c *UpdateCommand) updateBasicFields(id int, currentTask task.Task, newTitle string, done bool, priorityFlag string) error {
-	// Update title if provided
-	title := currentTask.Title
-	if newTitle != "" {
-		title = newTitle
+// parseChanges parses the flag values into the changes to apply
+func (c *UpdateCommand) parseChanges(cmd *flag.FlagSet, title string, done bool, priorityFlag, tags, estimate, dueDate, reminder string, removeDue, removeReminder bool) (*task.TaskChanges, error) {
+	changes := &task.TaskChanges{
+		Title:          title,
+		Complete:       done,
+		RemoveDueDate:  removeDue,
+		RemoveReminder: removeReminder,
 	}
 
 	// Update Priority if provided
-	var priority *task.TaskPriority
 	if priorityFlag != "" {
 		p, err := task.ParsePriority(priorityFlag)
 		if err != nil {
-			return c.presenter.PrintError("invalid priority: %v", err)
+			return nil, c.presenter.PrintError("invalid priority: %w", err)
 		}
-		priority = &p
+		changes.Priority = &p
 	}
 
-	// Update done status
-	newDone := done || currentTask.Done
-
-	// Update basic fields
-	if err := c.tm.UpdateTask(id, title, newDone, priority); err != nil {
-		return c.presenter.PrintError("error updating task: %v", err)
+	// An empty -tags value removes all the tags
+	if isFlagSet(cmd, "tags") {
+		changes.SetTags = true
+		changes.Tags = strings.Split(tags, ",")
 	}
 
-	return nil
-}
-
-// updateDueDate updates the due date of a task
-func (c *UpdateCommand) updateDueDate(id int, dueDate string, removeDue bool) error {
-	if removeDue {
-		if err := c.tm.RemoveDueDate(id); err != nil {
-			return c.presenter.PrintError("error removing due date: %v", err)
+	if estimate != "" {
+		d, err := task.ParseDuration(estimate)
+		if err != nil {
+			return nil, c.presenter.PrintError("invalid estimate: %w", err)
 		}
-	} else if dueDate != "" {
+		changes.Estimate = &d
+	}
+
+	if !removeDue && dueDate != "" {
 		due, err := task.ParseDateTime(dueDate)
 		if err != nil {
-			return c.presenter.PrintError("invalid due date: %v", err)
-		}
-		if err := c.tm.SetDueDate(id, due); err != nil {
-			return c.presenter.PrintError("error setting due date: %v", err)
+			return nil, c.presenter.PrintError("invalid due date: %w", err)
 		}
+		changes.DueDate = &due
 	}
-	return nil
-}
 
-// updateReminder updates the reminder of a task
-func (c *UpdateCommand) updateReminder(id int, reminder string, removeReminder bool) error {
-	if removeReminder {
-		if err := c.tm.RemoveReminder(id); err != nil {
-			return c.presenter.PrintError("error removing reminder: %v", err)
-		}
-	} else if reminder != "" {
+	if !removeReminder && reminder != "" {
 		rem, err := task.ParseDateTime(reminder)
 		if err != nil {
-			return c.presenter.PrintError("invalid reminder time: %v", err)
-		}
-		if err := c.tm.SetReminder(id, rem); err != nil {
-			return c.presenter.PrintError("error setting reminder: %v", err)
+			return nil, c.presenter.PrintError("invalid reminder time: %w", err)
 		}
+		changes.Reminder = &rem
 	}
-	return nil
+
+	return changes, nil
+}
+
+// isFlagSet reports whether a flag was given on the command line
+func isFlagSet(cmd *flag.FlagSet, name string) bool {
+	set := false
+	cmd.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
 
 // Help returns the help message for the update command
 func (c *UpdateCommand) Help() string {
-	return `Update an existing task
+	return `Update one or more existing tasks
 
 Usage:
-  task update <id> [flags]
+  task update <ids> [flags]
+  task update -where <filter> [flags]
+
+Arguments:
+  <ids>    Task IDs and ranges, e.g. 3 or 3,5,8-12
 
 Flags:
-  -title string      New task title
-  -priority string   Change priority: low, medium, high
+  -title string      New task title (single task only)
+  -priority string   Change priority: none, someday, low, medium, high,
+                     urgent
+  -tags string       Replace tags (comma separated, empty to clear)
+  -estimate string   Estimated effort, e.g. 2h or 1h30m (0 to remove)
   -done              Mark as completed
   -due string        Set due date (YYYY-MM-DD HH:MM)
   -reminder string   Set reminder (YYYY-MM-DD HH:MM)
   -remove-due        Remove due date
-  -remove-reminder   Remove reminder`
+  -remove-reminder   Remove reminder
+  -where string      Update the tasks matching a filter expression
+                     (see 'task help list')
+  -dry-run           Show the affected tasks without changing them
+  -yes               Don't ask for confirmation
+
+Updates on several tasks show the affected tasks and ask for
+confirmation. The changes are applied to all the tasks at once: when a
+value is invalid for any of them, no task is changed.`
 }
@@ -0,0 +1,302 @@
+// Package config reads the settings of the data directory, kept in
+// config.json next to the task files
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fileName is the name of the configuration file in the data directory
+const fileName = "config.json"
+
+// ErrInvalidConfig is returned when the configuration file has invalid settings
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// Hook events
+const (
+	EventAdd      = "add"
+	EventUpdate   = "update"
+	EventComplete = "complete"
+	EventDelete   = "delete"
+	EventPurge    = "purge"
+	EventOverdue  = "overdue"
+)
+
+// Events are the events hooks can be fired on
+var Events = []string{EventAdd, EventUpdate, EventComplete, EventDelete, EventPurge, EventOverdue}
+
+// Config holds the settings of the data directory
+type Config struct {
+	Hooks []Hook `json:"hooks,omitempty"`
+	// Priorities change the names and colors of the priorities, by their
+	// fixed name: none, someday, low, medium, high or urgent
+	Priorities map[string]PriorityStyle `json:"priorities,omitempty"`
+	Aging      *Aging                   `json:"aging,omitempty"`
+	Urgency    *Urgency                 `json:"urgency,omitempty"`
+}
+
+// PriorityStyle is how a priority is shown, empty values keep the default
+type PriorityStyle struct {
+	Name  string `json:"name,omitempty"`
+	Color string `json:"color,omitempty"`
+}
+
+// Urgency changes the weights of the urgency score, the ones not set keep
+// their default
+type Urgency struct {
+	// Priority are the weights of the priorities by their fixed name
+	Priority map[string]float64 `json:"priority,omitempty"`
+	Due      *float64           `json:"due,omitempty"`
+	Age      *float64           `json:"age,omitempty"`
+	// AgeMax is the age that gets the whole Age weight
+	AgeMax  Duration           `json:"age_max,omitempty"`
+	Blocked *float64           `json:"blocked,omitempty"`
+	Tags    map[string]float64 `json:"tags,omitempty"`
+}
+
+// Aging raises the priority of the pending tasks that keep the same one
+// for too long
+type Aging struct {
+	// After is the time without a priority change before raising it a level
+	After Duration `json:"after"`
+	// Max is the highest priority reached by aging, high when not set
+	Max string `json:"max,omitempty"`
+}
+
+// Hook runs a command or posts to a URL when one of its events happens.
+// Exactly one of Command and URL is set.
+type Hook struct {
+	Name    string   `json:"name,omitempty"`
+	Events  []string `json:"events"`
+	Command string   `json:"command,omitempty"`
+	URL     string   `json:"url,omitempty"`
+	// Timeout is the limit of every attempt, 10 seconds when not set
+	Timeout Duration `json:"timeout,omitempty"`
+	// Retries is the number of attempts made after a failed one
+	Retries int `json:"retries,omitempty"`
+}
+
+// DefaultHookTimeout is the timeout of the hooks that don't set one
+const DefaultHookTimeout = 10 * time.Second
+
+// Duration is a time.Duration written as a string like "30s" or "2m"
+type Duration time.Duration
+
+// UnmarshalJSON reads a duration string, days ("7d") and weeks ("2w")
+// included
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := task.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Path returns the path of the configuration file of a data directory
+func Path(dataDir string) string {
+	return filepath.Join(dataDir, fileName)
+}
+
+// Load reads the configuration of a data directory, a missing file is an
+// empty configuration
+func Load(dataDir string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(Path(dataDir))
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, fileName, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return cfg, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, fileName, err)
+	}
+	return cfg, nil
+}
+
+// validate checks the settings
+func (c Config) validate() error {
+	for i, h := range c.Hooks {
+		name := h.Name
+		if name == "" {
+			name = fmt.Sprintf("hook %d", i+1)
+		}
+
+		if (h.Command == "") == (h.URL == "") {
+			return fmt.Errorf("%s: set either command or url", name)
+		}
+		if len(h.Events) == 0 {
+			return fmt.Errorf("%s: no events", name)
+		}
+		for _, e := range h.Events {
+			if !IsEvent(e) {
+				return fmt.Errorf("%s: unknown event: %s", name, e)
+			}
+		}
+		if h.Timeout < 0 || h.Retries < 0 {
+			return fmt.Errorf("%s: timeout and retries can't be negative", name)
+		}
+	}
+
+	names := make(map[string]string)
+	for key, style := range c.Priorities {
+		p, err := task.ParsePriority(key)
+		if err != nil || p.Key() != key {
+			return fmt.Errorf("priorities: unknown priority: %s (use none, someday, low, medium, high or urgent)", key)
+		}
+		if _, ok := task.Colors[style.Color]; style.Color != "" && !ok {
+			return fmt.Errorf("priorities: %s: unknown color: %s", key, style.Color)
+		}
+		if style.Name == "" {
+			continue
+		}
+		// A name can't be the fixed name of another priority or be repeated
+		name := strings.ToLower(style.Name)
+		if other, err := task.ParsePriority(name); err == nil && other.Key() == name && other != p {
+			return fmt.Errorf("priorities: %s: the name %s is the one of another priority", key, style.Name)
+		}
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("priorities: %s and %s have the same name", other, key)
+		}
+		names[name] = key
+	}
+
+	if c.Urgency != nil {
+		for key := range c.Urgency.Priority {
+			if p, err := task.ParsePriority(key); err != nil || p.Key() != key {
+				return fmt.Errorf("urgency: unknown priority: %s", key)
+			}
+		}
+		if c.Urgency.AgeMax < 0 {
+			return fmt.Errorf("urgency: age_max can't be negative")
+		}
+	}
+
+	if c.Aging != nil {
+		if c.Aging.After <= 0 {
+			return fmt.Errorf("aging: after must be a positive duration")
+		}
+		if c.Aging.Max != "" {
+			if p, err := task.ParsePriority(c.Aging.Max); err != nil || p.Key() != c.Aging.Max {
+				return fmt.Errorf("aging: unknown priority: %s", c.Aging.Max)
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyPriorities sets the names and colors of the priorities
+func (c Config) ApplyPriorities() error {
+	for key, style := range c.Priorities {
+		p, err := task.ParsePriority(key)
+		if err != nil {
+			return err
+		}
+		if err := task.SetPriorityStyle(p, task.PriorityStyle{Name: style.Name, Color: style.Color}); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, fileName, err)
+		}
+	}
+	return nil
+}
+
+// UrgencyWeights returns the weights of the urgency score, the defaults
+// changed by the configuration
+func (c Config) UrgencyWeights() task.UrgencyWeights {
+	w := task.DefaultUrgencyWeights()
+	u := c.Urgency
+	if u == nil {
+		return w
+	}
+
+	for key, weight := range u.Priority {
+		p, _ := task.ParsePriority(key)
+		w.Priority[p] = weight
+	}
+	if u.Due != nil {
+		w.Due = *u.Due
+	}
+	if u.Age != nil {
+		w.Age = *u.Age
+	}
+	if u.Blocked != nil {
+		w.Blocked = *u.Blocked
+	}
+	if u.AgeMax > 0 {
+		w.AgeMax = time.Duration(u.AgeMax)
+	}
+	for tag, weight := range u.Tags {
+		w.Tags[strings.ToLower(strings.TrimPrefix(tag, "#"))] = weight
+	}
+	return w
+}
+
+// PriorityAging returns the priority aging of the configuration, with an
+// After of zero when aging is off
+func (c Config) PriorityAging() task.PriorityAging {
+	aging := task.PriorityAging{Max: task.PriorityHigh}
+	if c.Aging == nil {
+		return aging
+	}
+	aging.After = time.Duration(c.Aging.After)
+	if c.Aging.Max != "" {
+		aging.Max, _ = task.ParsePriority(c.Aging.Max)
+	}
+	return aging
+}
+
+// IsEvent tells whether a hook event exists
+func IsEvent(e string) bool {
+	for _, known := range Events {
+		if e == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Fires tells whether the hook runs on an event
+func (h Hook) Fires(event string) bool {
+	for _, e := range h.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// TimeoutOrDefault returns the timeout of the hook
+func (h Hook) TimeoutOrDefault() time.Duration {
+	if h.Timeout == 0 {
+		return DefaultHookTimeout
+	}
+	return time.Duration(h.Timeout)
+}
+
+// Target describes what the hook runs
+func (h Hook) Target() string {
+	if h.URL != "" {
+		return "POST " + h.URL
+	}
+	return h.Command
+}
@@ -0,0 +1,382 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// dialTimeout is how long Dial waits for the daemon
+const dialTimeout = time.Second
+
+// ErrClosed is returned by the calls made after the connection to the daemon
+// is lost
+var ErrClosed = errors.New("connection to the daemon closed")
+
+// Client is a task manager that forwards every call to a daemon. The methods
+// of ITaskManager that can't return an error return zero values when the
+// daemon can't be reached, the error is then returned by the next call that
+// can fail, like SaveTasks.
+type Client struct {
+	nc  net.Conn
+	wmu sync.Mutex
+	enc *json.Encoder
+
+	mu      sync.Mutex
+	pending map[string]chan response
+	nextID  int
+	err     error
+
+	subscribers    map[int]func(task.Event)
+	nextSubscriber int
+	events         chan task.Event
+}
+
+// Verificamos que Client implementa ITaskManager
+var _ task.ITaskManager = (*Client)(nil)
+
+// Dial connects to the daemon listening on the socket
+func Dial(socket string) (*Client, error) {
+	nc, err := net.DialTimeout("unix", socket, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		nc:          nc,
+		enc:         json.NewEncoder(nc),
+		pending:     make(map[string]chan response),
+		subscribers: make(map[int]func(task.Event)),
+		events:      make(chan task.Event, eventBuffer),
+	}
+	go c.read()
+	go c.dispatch()
+	return c, nil
+}
+
+// Close closes the connection to the daemon
+func (c *Client) Close() error {
+	return c.nc.Close()
+}
+
+// Err returns the error that closed the connection, if any
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// read receives the responses and events sent by the daemon
+func (c *Client) read() {
+	dec := json.NewDecoder(c.nc)
+	for {
+		var resp response
+		if err := dec.Decode(&resp); err != nil {
+			c.fail(fmt.Errorf("%w: %v", ErrClosed, err))
+			return
+		}
+
+		if resp.Method == methodEvent {
+			var e task.Event
+			if err := json.Unmarshal(resp.Params, &e); err == nil {
+				// The responses keep flowing when the subscribers fall
+				// behind, or call the client themselves: the events that
+				// don't fit are dropped
+				select {
+				case c.events <- e:
+				default:
+				}
+			}
+			continue
+		}
+
+		c.mu.Lock()
+		ch, ok := c.pending[string(resp.ID)]
+		delete(c.pending, string(resp.ID))
+		c.mu.Unlock()
+		if ok {
+			ch <- resp
+		}
+	}
+}
+
+// dispatch calls the subscribers with the events, out of the reading
+// goroutine so they can call the client
+func (c *Client) dispatch() {
+	for e := range c.events {
+		c.mu.Lock()
+		subscribers := make([]func(task.Event), 0, len(c.subscribers))
+		for _, fn := range c.subscribers {
+			subscribers = append(subscribers, fn)
+		}
+		c.mu.Unlock()
+
+		for _, fn := range subscribers {
+			fn(e)
+		}
+	}
+}
+
+// fail records the error that closed the connection and fails the pending
+// calls
+func (c *Client) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err == nil {
+		c.err = err
+	}
+	for id, ch := range c.pending {
+		ch <- response{Error: &Error{Code: CodeInternalError, Message: c.err.Error()}}
+		delete(c.pending, id)
+	}
+	close(c.events)
+}
+
+// call sends a request and decodes its result
+func (c *Client) call(method string, p *params, result interface{}) error {
+	c.mu.Lock()
+	if c.err != nil {
+		err := c.err
+		c.mu.Unlock()
+		return err
+	}
+	c.nextID++
+	id := strconv.Itoa(c.nextID)
+	ch := make(chan response, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	req := request{JSONRPC: "2.0", ID: json.RawMessage(id), Method: method}
+	if p != nil {
+		data, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		req.Params = data
+	}
+
+	c.wmu.Lock()
+	err := c.enc.Encode(req)
+	c.wmu.Unlock()
+	if err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return fmt.Errorf("%w: %v", ErrClosed, err)
+	}
+
+	resp := <-ch
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if result != nil && len(resp.Result) > 0 {
+		return json.Unmarshal(resp.Result, result)
+	}
+	return nil
+}
+
+// Subscribe registers a function called for every change made to the tasks
+// of the daemon, by this client or any other. Functions are called from a
+// single goroutine, in the order of the changes. Events are dropped while
+// 256 are waiting for the functions.
+func (c *Client) Subscribe(fn func(task.Event)) func() {
+	c.mu.Lock()
+	first := len(c.subscribers) == 0
+	id := c.nextSubscriber
+	c.nextSubscriber++
+	c.subscribers[id] = fn
+	c.mu.Unlock()
+
+	if first {
+		c.call(methodSubscribe, nil, nil)
+	}
+
+	return func() {
+		c.mu.Lock()
+		_, ok := c.subscribers[id]
+		delete(c.subscribers, id)
+		last := ok && len(c.subscribers) == 0
+		c.mu.Unlock()
+
+		if last {
+			c.call(methodUnsubscribe, nil, nil)
+		}
+	}
+}
+
+// AddTask creates a new task
+func (c *Client) AddTask(title string, priority task.TaskPriority) task.Task {
+	var t task.Task
+	c.call("AddTask", &params{Title: title, Priority: &priority}, &t)
+	return t
+}
+
+// ImportTask adds a task created elsewhere
+func (c *Client) ImportTask(t task.Task) (task.Task, error) {
+	var imported task.Task
+	err := c.call("ImportTask", &params{Task: &t}, &imported)
+	return imported, err
+}
+
+// GetTaskByID returns a task
+func (c *Client) GetTaskByID(id int) (task.Task, error) {
+	var t task.Task
+	err := c.call("GetTaskByID", &params{ID: id}, &t)
+	return t, err
+}
+
+// DeleteTask moves a task to the trash
+func (c *Client) DeleteTask(id int) error {
+	return c.call("DeleteTask", &params{ID: id}, nil)
+}
+
+// RestoreTask moves a task back from the trash
+func (c *Client) RestoreTask(id int) error {
+	return c.call("RestoreTask", &params{ID: id}, nil)
+}
+
+// GetTrashedTasks returns the tasks in the trash
+func (c *Client) GetTrashedTasks() []task.Task {
+	var tasks []task.Task
+	c.call("GetTrashedTasks", nil, &tasks)
+	return tasks
+}
+
+// EmptyTrash removes the tasks deleted for longer than olderThan
+func (c *Client) EmptyTrash(olderThan time.Duration) int {
+	var removed int
+	c.call("EmptyTrash", &params{Duration: olderThan}, &removed)
+	return removed
+}
+
+// ArchiveTasks moves completed tasks to the archive
+func (c *Client) ArchiveTasks(ids []int, olderThan time.Duration) ([]task.Task, error) {
+	var archived []task.Task
+	err := c.call("ArchiveTasks", &params{IDs: ids, Duration: olderThan}, &archived)
+	return archived, err
+}
+
+// UnarchiveTask moves a task back from the archive
+func (c *Client) UnarchiveTask(id int) error {
+	return c.call("UnarchiveTask", &params{ID: id}, nil)
+}
+
+// GetArchivedTasks returns the archived tasks
+func (c *Client) GetArchivedTasks() []task.Task {
+	var tasks []task.Task
+	c.call("GetArchivedTasks", nil, &tasks)
+	return tasks
+}
+
+// UpdateTask changes the title, status and priority of a task
+func (c *Client) UpdateTask(id int, title string, done bool, priority *task.TaskPriority) error {
+	return c.call("UpdateTask", &params{ID: id, Title: title, Done: done, Priority: priority}, nil)
+}
+
+// UpdateTasks makes the same changes to several tasks, all or none
+func (c *Client) UpdateTasks(ids []int, changes task.TaskChanges) ([]task.Task, error) {
+	var updated []task.Task
+	err := c.call("UpdateTasks", &params{IDs: ids, Changes: &changes}, &updated)
+	return updated, err
+}
+
+// SetTags replaces the tags of a task
+func (c *Client) SetTags(id int, tags []string) error {
+	return c.call("SetTags", &params{ID: id, Tags: tags}, nil)
+}
+
+// CompleteTask marks a task as done
+func (c *Client) CompleteTask(id int, note string) error {
+	return c.call("CompleteTask", &params{ID: id, Note: note}, nil)
+}
+
+// ReopenTask marks a completed task as pending
+func (c *Client) ReopenTask(id int, note string) error {
+	return c.call("ReopenTask", &params{ID: id, Note: note}, nil)
+}
+
+// SetDueDate sets the due date of a task
+func (c *Client) SetDueDate(id int, date time.Time) error {
+	return c.call("SetDueDate", &params{ID: id, Date: date}, nil)
+}
+
+// SetReminder sets the reminder of a task
+func (c *Client) SetReminder(id int, date time.Time) error {
+	return c.call("SetReminder", &params{ID: id, Date: date}, nil)
+}
+
+// RemoveDueDate removes the due date of a task
+func (c *Client) RemoveDueDate(id int) error {
+	return c.call("RemoveDueDate", &params{ID: id}, nil)
+}
+
+// RemoveReminder removes the reminder of a task
+func (c *Client) RemoveReminder(id int) error {
+	return c.call("RemoveReminder", &params{ID: id}, nil)
+}
+
+// StartTimer starts a timer on a task
+func (c *Client) StartTimer(id int) error {
+	return c.call("StartTimer", &params{ID: id}, nil)
+}
+
+// StopTimer stops the running timer and returns its task
+func (c *Client) StopTimer() (task.Task, error) {
+	var t task.Task
+	err := c.call("StopTimer", nil, &t)
+	return t, err
+}
+
+// ActiveTimer returns the task with a running timer
+func (c *Client) ActiveTimer() (task.Task, bool) {
+	var active activeTimer
+	c.call("ActiveTimer", nil, &active)
+	return active.Task, active.Running
+}
+
+// LogTime records time spent on a task
+func (c *Client) LogTime(id int, d time.Duration, note string) error {
+	return c.call("LogTime", &params{ID: id, Duration: d, Note: note}, nil)
+}
+
+// SetEstimate sets the estimated time of a task
+func (c *Client) SetEstimate(id int, estimate time.Duration) error {
+	return c.call("SetEstimate", &params{ID: id, Duration: estimate}, nil)
+}
+
+// GetTasksSorted returns the active tasks sorted
+func (c *Client) GetTasksSorted(byPriority, byDueDate bool) []task.Task {
+	var tasks []task.Task
+	c.call("GetTasksSorted", &params{ByPriority: byPriority, ByDueDate: byDueDate}, &tasks)
+	return tasks
+}
+
+// GetTasksByTimeStatus returns the tasks with a time status
+func (c *Client) GetTasksByTimeStatus(status task.TimeStatus) []task.Task {
+	var tasks []task.Task
+	c.call("GetTasksByTimeStatus", &params{Status: status}, &tasks)
+	return tasks
+}
+
+// Merge merges the tasks of another copy of the data
+func (c *Client) Merge(base, other []task.Task) task.MergeResult {
+	var result task.MergeResult
+	c.call("Merge", &params{Base: base, Other: other}, &result)
+	return result
+}
+
+// LoadTasks makes the daemon read the tasks files again
+func (c *Client) LoadTasks() error {
+	return c.call("LoadTasks", nil, nil)
+}
+
+// SaveTasks makes the daemon write the tasks files
+func (c *Client) SaveTasks() error {
+	return c.call("SaveTasks", nil, nil)
+}
@@ -0,0 +1,155 @@
+// Package daemon serves a task manager over a Unix socket with JSON-RPC 2.0,
+// and provides a client that implements task.ITaskManager on top of it.
+//
+// Every method of ITaskManager is a JSON-RPC method with the same name. Its
+// arguments are sent as an object with the fields of params, for example
+// {"id": 3, "note": "Released"} for CompleteTask. After a call to Subscribe
+// the connection also receives an "Event" notification for every change.
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/kubaliski/task-cli/internal/task"
+	"path/filepath"
+	"time"
+)
+
+// socketFileName is the name of the socket in the data directory
+const socketFileName = "daemon.sock"
+
+// SocketPath returns the path of the daemon socket for a data directory
+func SocketPath(dataDir string) string {
+	return filepath.Join(dataDir, socketFileName)
+}
+
+// Methods that are not part of ITaskManager
+const (
+	methodSubscribe   = "Subscribe"
+	methodUnsubscribe = "Unsubscribe"
+	methodEvent       = "Event"
+)
+
+// request is a JSON-RPC request, or a notification when it has no ID
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is a JSON-RPC response. Notifications sent by the server use the
+// same message with a method and no ID.
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// params are the arguments of the methods, each method uses some of them
+type params struct {
+	ID         int                `json:"id,omitempty"`
+	IDs        []int              `json:"ids,omitempty"`
+	Title      string             `json:"title,omitempty"`
+	Priority   *task.TaskPriority `json:"priority,omitempty"`
+	Done       bool               `json:"done,omitempty"`
+	Note       string             `json:"note,omitempty"`
+	Tags       []string           `json:"tags,omitempty"`
+	Date       time.Time          `json:"date,omitempty"`
+	Duration   time.Duration      `json:"duration,omitempty"`
+	Task       *task.Task         `json:"task,omitempty"`
+	Changes    *task.TaskChanges  `json:"changes,omitempty"`
+	ByPriority bool               `json:"by_priority,omitempty"`
+	ByDueDate  bool               `json:"by_due_date,omitempty"`
+	Status     task.TimeStatus    `json:"status,omitempty"`
+	Base       []task.Task        `json:"base,omitempty"`
+	Other      []task.Task        `json:"other,omitempty"`
+}
+
+// activeTimer is the result of ActiveTimer
+type activeTimer struct {
+	Task    task.Task `json:"task"`
+	Running bool      `json:"running"`
+}
+
+// Error codes, the standard JSON-RPC ones and one per class of task error
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+
+	CodeError            = -32000
+	CodeNotFound         = -32001
+	CodeTitleRequired    = -32002
+	CodeInvalidPriority  = -32003
+	CodeInvalidDate      = -32004
+	CodeInvalidTimeOrder = -32005
+	CodeInvalidDuration  = -32006
+	CodeInvalidFilter    = -32007
+	CodeInvalidID        = -32008
+	CodeInvalidValue     = -32009
+	CodeAlreadyCompleted = -32010
+	CodeNotCompleted     = -32011
+	CodeTimerRunning     = -32012
+	CodeNoTimer          = -32013
+)
+
+// errorCodes maps the task errors to their codes
+var errorCodes = []struct {
+	code int
+	err  error
+}{
+	{CodeNotFound, task.ErrNotFound},
+	{CodeTitleRequired, task.ErrTitleRequired},
+	{CodeInvalidPriority, task.ErrInvalidPriority},
+	{CodeInvalidDate, task.ErrInvalidDate},
+	{CodeInvalidTimeOrder, task.ErrInvalidTimeOrder},
+	{CodeInvalidDuration, task.ErrInvalidDuration},
+	{CodeInvalidFilter, task.ErrInvalidFilter},
+	{CodeInvalidID, task.ErrInvalidID},
+	{CodeInvalidValue, task.ErrInvalidValue},
+	{CodeAlreadyCompleted, task.ErrAlreadyCompleted},
+	{CodeNotCompleted, task.ErrNotCompleted},
+	{CodeTimerRunning, task.ErrTimerRunning},
+	{CodeNoTimer, task.ErrNoTimer},
+}
+
+// Error is a JSON-RPC error. The errors of the task manager keep their
+// message and match their task error with errors.Is on the client side.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the task error of the code, if any
+func (e *Error) Unwrap() error {
+	for _, c := range errorCodes {
+		if c.code == e.Code {
+			return c.err
+		}
+	}
+	return nil
+}
+
+// newError converts an error of the task manager to a JSON-RPC error
+func newError(err error) *Error {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return &Error{Code: c.code, Message: err.Error()}
+		}
+	}
+	return &Error{Code: CodeError, Message: err.Error()}
+}
@@ -0,0 +1,354 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/kubaliski/task-cli/internal/task"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// writeTimeout is how long the server waits for a client to read a
+	// message before closing its connection
+	writeTimeout = 5 * time.Second
+	// eventBuffer is how many events wait to be sent to a client, or to be
+	// passed to the subscribers of a Client
+	eventBuffer = 256
+)
+
+// handler runs a method with its arguments and returns its result
+type handler func(tm task.ITaskManager, p params) (interface{}, error)
+
+// handlers are the methods of ITaskManager. Those that change the tasks are
+// listed in savedMethods.
+var handlers = map[string]handler{
+	"AddTask": func(tm task.ITaskManager, p params) (interface{}, error) {
+		priority := task.DefaultPriority
+		if p.Priority != nil {
+			priority = *p.Priority
+		}
+		return tm.AddTask(p.Title, priority), nil
+	},
+	"ImportTask": func(tm task.ITaskManager, p params) (interface{}, error) {
+		if p.Task == nil {
+			return nil, &Error{Code: CodeInvalidParams, Message: "task is required"}
+		}
+		return tm.ImportTask(*p.Task)
+	},
+	"GetTaskByID": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return tm.GetTaskByID(p.ID)
+	},
+	"DeleteTask": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return nil, tm.DeleteTask(p.ID)
+	},
+	"RestoreTask": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return nil, tm.RestoreTask(p.ID)
+	},
+	"GetTrashedTasks": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return tm.GetTrashedTasks(), nil
+	},
+	"EmptyTrash": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return tm.EmptyTrash(p.Duration), nil
+	},
+	"ArchiveTasks": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return tm.ArchiveTasks(p.IDs, p.Duration)
+	},
+	"UnarchiveTask": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return nil, tm.UnarchiveTask(p.ID)
+	},
+	"GetArchivedTasks": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return tm.GetArchivedTasks(), nil
+	},
+	"UpdateTask": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return nil, tm.UpdateTask(p.ID, p.Title, p.Done, p.Priority)
+	},
+	"UpdateTasks": func(tm task.ITaskManager, p params) (interface{}, error) {
+		if p.Changes == nil {
+			return nil, &Error{Code: CodeInvalidParams, Message: "changes are required"}
+		}
+		return tm.UpdateTasks(p.IDs, *p.Changes)
+	},
+	"SetTags": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return nil, tm.SetTags(p.ID, p.Tags)
+	},
+	"CompleteTask": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return nil, tm.CompleteTask(p.ID, p.Note)
+	},
+	"ReopenTask": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return nil, tm.ReopenTask(p.ID, p.Note)
+	},
+	"SetDueDate": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return nil, tm.SetDueDate(p.ID, p.Date)
+	},
+	"SetReminder": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return nil, tm.SetReminder(p.ID, p.Date)
+	},
+	"RemoveDueDate": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return nil, tm.RemoveDueDate(p.ID)
+	},
+	"RemoveReminder": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return nil, tm.RemoveReminder(p.ID)
+	},
+	"StartTimer": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return nil, tm.StartTimer(p.ID)
+	},
+	"StopTimer": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return tm.StopTimer()
+	},
+	"ActiveTimer": func(tm task.ITaskManager, p params) (interface{}, error) {
+		t, running := tm.ActiveTimer()
+		return activeTimer{Task: t, Running: running}, nil
+	},
+	"LogTime": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return nil, tm.LogTime(p.ID, p.Duration, p.Note)
+	},
+	"SetEstimate": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return nil, tm.SetEstimate(p.ID, p.Duration)
+	},
+	"GetTasksSorted": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return tm.GetTasksSorted(p.ByPriority, p.ByDueDate), nil
+	},
+	"GetTasksByTimeStatus": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return tm.GetTasksByTimeStatus(p.Status), nil
+	},
+	"Merge": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return tm.Merge(p.Base, p.Other), nil
+	},
+	"LoadTasks": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return nil, tm.LoadTasks()
+	},
+	"SaveTasks": func(tm task.ITaskManager, p params) (interface{}, error) {
+		return nil, tm.SaveTasks()
+	},
+}
+
+// savedMethods change the tasks, they are saved right after so no change is
+// lost when a client doesn't call SaveTasks
+var savedMethods = map[string]bool{
+	"AddTask":        true,
+	"ImportTask":     true,
+	"DeleteTask":     true,
+	"RestoreTask":    true,
+	"EmptyTrash":     true,
+	"ArchiveTasks":   true,
+	"UnarchiveTask":  true,
+	"UpdateTask":     true,
+	"UpdateTasks":    true,
+	"SetTags":        true,
+	"CompleteTask":   true,
+	"ReopenTask":     true,
+	"SetDueDate":     true,
+	"SetReminder":    true,
+	"RemoveDueDate":  true,
+	"RemoveReminder": true,
+	"StartTimer":     true,
+	"StopTimer":      true,
+	"LogTime":        true,
+	"SetEstimate":    true,
+	"Merge":          true,
+}
+
+// Server serves a task manager to the clients connected to a listener
+type Server struct {
+	tm task.ITaskManager
+
+	mu       sync.Mutex
+	conns    map[*conn]bool
+	closed   bool
+	listener net.Listener
+}
+
+// NewServer creates a server for the task manager
+func NewServer(tm task.ITaskManager) *Server {
+	return &Server{
+		tm:    tm,
+		conns: make(map[*conn]bool),
+	}
+}
+
+// Serve accepts connections until the listener or the server is closed
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	s.listener = l
+	s.mu.Unlock()
+
+	for {
+		nc, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.serveConn(nc)
+	}
+}
+
+// Close stops accepting connections and closes the open ones
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for c := range s.conns {
+		c.nc.Close()
+	}
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+// conn is a client connection
+type conn struct {
+	nc          net.Conn
+	wmu         sync.Mutex
+	enc         *json.Encoder
+	unsubscribe func()
+	// events are the events waiting to be sent by writeEvents
+	events chan task.Event
+	done   chan struct{}
+}
+
+// serveConn reads the requests of a connection and answers them in order
+func (s *Server) serveConn(nc net.Conn) {
+	c := &conn{
+		nc:     nc,
+		enc:    json.NewEncoder(nc),
+		events: make(chan task.Event, eventBuffer),
+		done:   make(chan struct{}),
+	}
+	s.mu.Lock()
+	s.conns[c] = true
+	s.mu.Unlock()
+	go c.writeEvents()
+
+	defer func() {
+		if c.unsubscribe != nil {
+			c.unsubscribe()
+		}
+		close(c.done)
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		nc.Close()
+	}()
+
+	dec := json.NewDecoder(nc)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				c.write(response{Error: &Error{Code: CodeParseError, Message: err.Error()}})
+			}
+			return
+		}
+
+		var req request
+		if err := json.Unmarshal(raw, &req); err != nil || req.JSONRPC != "2.0" || req.Method == "" {
+			c.write(response{ID: req.ID, Error: &Error{Code: CodeInvalidRequest, Message: "invalid request"}})
+			continue
+		}
+
+		result, rpcErr := s.handle(c, req)
+		// Notifications get no response
+		if len(req.ID) == 0 || bytes.Equal(req.ID, []byte("null")) {
+			continue
+		}
+		resp := response{ID: req.ID, Error: rpcErr}
+		if rpcErr == nil {
+			resp.Result, _ = json.Marshal(result)
+		}
+		if err := c.write(resp); err != nil {
+			return
+		}
+	}
+}
+
+// handle runs a request
+func (s *Server) handle(c *conn, req request) (interface{}, *Error) {
+	switch req.Method {
+	case methodSubscribe:
+		if c.unsubscribe == nil {
+			c.unsubscribe = s.tm.Subscribe(c.notify)
+		}
+		return nil, nil
+	case methodUnsubscribe:
+		if c.unsubscribe != nil {
+			c.unsubscribe()
+			c.unsubscribe = nil
+		}
+		return nil, nil
+	}
+
+	h, ok := handlers[req.Method]
+	if !ok {
+		return nil, &Error{Code: CodeMethodNotFound, Message: "method not found: " + req.Method}
+	}
+
+	var p params
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+		}
+	}
+
+	result, err := h(s.tm, p)
+	if err != nil {
+		return nil, newError(err)
+	}
+	if savedMethods[req.Method] {
+		if err := s.tm.SaveTasks(); err != nil {
+			return nil, newError(err)
+		}
+	}
+	return result, nil
+}
+
+// notify queues an event for the client. It runs on the goroutine that
+// changed the tasks, so it never waits for the client.
+func (c *conn) notify(e task.Event) {
+	select {
+	case c.events <- e:
+	default:
+		// A client that doesn't read its events is disconnected
+		c.nc.Close()
+	}
+}
+
+// writeEvents sends the queued events to the client until the connection
+// is closed
+func (c *conn) writeEvents() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case e := <-c.events:
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			if err := c.write(response{Method: methodEvent, Params: data}); err != nil {
+				c.nc.Close()
+				return
+			}
+		}
+	}
+}
+
+// write sends a message to the client
+func (c *conn) write(resp response) error {
+	resp.JSONRPC = "2.0"
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	c.nc.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.enc.Encode(resp)
+}
@@ -0,0 +1,184 @@
+package formats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"io"
+	"strings"
+	"time"
+)
+
+// CSVFields are the task fields that can be read from a CSV column
+var CSVFields = []string{"title", "priority", "due", "reminder", "tags", "done", "created"}
+
+// ParseCSVMapping parses a column mapping like "title=Name,due=Deadline".
+// Fields that aren't mapped are read from the column with their name.
+func ParseCSVMapping(s string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	if s == "" {
+		return mapping, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		field, column, ok := strings.Cut(pair, "=")
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !ok || column == "" {
+			return nil, fmt.Errorf("invalid column mapping: %s", pair)
+		}
+		if !isCSVField(field) {
+			return nil, fmt.Errorf("unknown task field: %s (expected one of %s)", field, strings.Join(CSVFields, ", "))
+		}
+		mapping[field] = strings.TrimSpace(column)
+	}
+	return mapping, nil
+}
+
+// CSVDecoder returns a decoder of CSV files with a header row, using the
+// mapping from task fields to column names
+func CSVDecoder(mapping map[string]string) Decoder {
+	return func(r io.Reader) ([]task.Task, []RowError, error) {
+		return decodeCSV(r, mapping)
+	}
+}
+
+// decodeCSV reads the tasks of a CSV file
+func decodeCSV(r io.Reader, mapping map[string]string) ([]task.Task, []RowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading CSV header: %v", err)
+	}
+
+	columns, err := csvColumns(header, mapping)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tasks []task.Task
+	var rowErrors []RowError
+	row := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		row++
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: row, Err: err})
+			continue
+		}
+
+		t, err := csvRecordToTask(record, columns)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: row, Err: err})
+			continue
+		}
+		tasks = append(tasks, t)
+	}
+
+	return tasks, rowErrors, nil
+}
+
+// csvColumns returns the position of the column of each mapped field
+func csvColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	positions := make(map[string]int)
+	for i, name := range header {
+		positions[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	columns := make(map[string]int)
+	for _, field := range CSVFields {
+		column, mapped := mapping[field]
+		if !mapped {
+			column = field
+		}
+		i, ok := positions[strings.ToLower(column)]
+		if !ok {
+			if mapped {
+				return nil, fmt.Errorf("column %q not found in CSV header", column)
+			}
+			continue
+		}
+		columns[field] = i
+	}
+
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("no title column, map one with title=<column>")
+	}
+	return columns, nil
+}
+
+// csvRecordToTask converts a CSV record
+func csvRecordToTask(record []string, columns map[string]int) (task.Task, error) {
+	value := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	t := task.Task{
+		Title:    value("title"),
+		Priority: task.DefaultPriority,
+	}
+	if t.Title == "" {
+		return task.Task{}, fmt.Errorf("empty title")
+	}
+
+	if p := value("priority"); p != "" {
+		priority, err := task.ParsePriority(p)
+		if err != nil {
+			return task.Task{}, err
+		}
+		t.Priority = priority
+	}
+
+	for field, target := range map[string]**time.Time{"due": &t.DueDate, "reminder": &t.Reminder} {
+		if v := value(field); v != "" {
+			date, err := task.ParseDateTime(v)
+			if err != nil {
+				return task.Task{}, fmt.Errorf("invalid %s date: %v", field, err)
+			}
+			*target = &date
+		}
+	}
+
+	if v := value("created"); v != "" {
+		created, err := task.ParseDateTime(v)
+		if err != nil {
+			return task.Task{}, fmt.Errorf("invalid created date: %v", err)
+		}
+		t.CreatedAt = created
+	}
+
+	if v := value("tags"); v != "" {
+		t.Tags = strings.FieldsFunc(v, func(r rune) bool {
+			return r == ',' || r == ';' || r == ' '
+		})
+	}
+
+	switch strings.ToLower(value("done")) {
+	case "", "0", "false", "no", "pending", "open":
+	case "1", "true", "yes", "x", "done", "completed":
+		t.Done = true
+	default:
+		return task.Task{}, fmt.Errorf("invalid done value: %s", value("done"))
+	}
+
+	return t, nil
+}
+
+// isCSVField reports whether the field can be read from a CSV column
+func isCSVField(field string) bool {
+	for _, f := range CSVFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package formats
+
+import (
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"io"
+)
+
+// RowError describes an entry of the input that couldn't be read.
+// Decoders skip these entries and keep reading.
+type RowError struct {
+	Row int
+	Err error
+}
+
+// Error returns the error message with the row number
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+// Decoder reads tasks from another application's format. Tasks have no ID,
+// the ones with an UID are matched with the existing tasks.
+type Decoder func(r io.Reader) ([]task.Task, []RowError, error)
+
+// Encoder writes tasks in another application's format
+type Encoder func(w io.Writer, tasks []task.Task) error
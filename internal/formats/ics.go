@@ -0,0 +1,482 @@
+// Package formats converts tasks to and from other applications' formats
+package formats
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	icsDateTime      = "20060102T150405Z"
+	icsLocalDateTime = "20060102T150405"
+	icsDate          = "20060102"
+	icsMaxLineLength = 75
+)
+
+// TaskUID returns the iCalendar UID of a task. Tasks without an UID of
+// their own get one derived from their ID.
+func TaskUID(t task.Task) string {
+	if t.UID != "" {
+		return t.UID
+	}
+	return fmt.Sprintf("task-%d@task-cli", t.ID)
+}
+
+// EncodeICS writes the tasks as an iCalendar file of VTODO entries
+func EncodeICS(w io.Writer, tasks []task.Task) error {
+	iw := &icsWriter{w: bufio.NewWriter(w)}
+	now := time.Now()
+
+	iw.line("BEGIN", "VCALENDAR")
+	iw.line("VERSION", "2.0")
+	iw.line("PRODID", "-//task-cli//task-cli//EN")
+
+	for _, t := range tasks {
+		iw.line("BEGIN", "VTODO")
+		iw.line("UID", TaskUID(t))
+		iw.line("DTSTAMP", formatICSTime(now))
+		iw.line("CREATED", formatICSTime(t.CreatedAt))
+		iw.line("SUMMARY", escapeICSText(t.Title))
+		iw.line("PRIORITY", strconv.Itoa(icsPriority(t.Priority)))
+
+		if t.Done {
+			iw.line("STATUS", "COMPLETED")
+			iw.line("COMPLETED", formatICSTime(t.CompletedAt))
+		} else {
+			iw.line("STATUS", "NEEDS-ACTION")
+		}
+
+		if len(t.Tags) > 0 {
+			escaped := make([]string, len(t.Tags))
+			for i, tag := range t.Tags {
+				escaped[i] = escapeICSText(tag)
+			}
+			iw.line("CATEGORIES", strings.Join(escaped, ","))
+		}
+
+		// Due dates and reminders are wall-clock times, written as floating
+		// times that calendars show as they are in any time zone
+		if t.DueDate != nil {
+			iw.line("DUE", formatICSFloating(*t.DueDate))
+		}
+
+		if t.Reminder != nil {
+			iw.line("BEGIN", "VALARM")
+			iw.line("ACTION", "DISPLAY")
+			iw.line("DESCRIPTION", escapeICSText(t.Title))
+			if t.DueDate != nil {
+				// Relative to the due date, so it follows it like the reminder
+				iw.line("TRIGGER;RELATED=END", formatICSDuration(t.Reminder.Sub(*t.DueDate)))
+			} else {
+				iw.line("TRIGGER;VALUE=DATE-TIME", formatICSFloating(*t.Reminder))
+			}
+			iw.line("END", "VALARM")
+		}
+
+		iw.line("END", "VTODO")
+	}
+
+	iw.line("END", "VCALENDAR")
+	if iw.err != nil {
+		return iw.err
+	}
+	return iw.w.Flush()
+}
+
+// DecodeICS reads the VTODO entries of an iCalendar file. Invalid entries
+// are reported by the line where they start.
+func DecodeICS(r io.Reader) ([]task.Task, []RowError, error) {
+	lines, err := unfoldICSLines(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tasks []task.Task
+	var rowErrors []RowError
+	var current *task.Task
+	var currentErr error
+	var trigger string
+	var triggerAbsolute bool
+	start := 0
+	inAlarm := false
+
+	for n, line := range lines {
+		name, params, value, err := parseICSLine(line)
+		if err != nil {
+			if current != nil && currentErr == nil {
+				currentErr = err
+			}
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && value == "VTODO":
+			current = &task.Task{Priority: task.DefaultPriority}
+			currentErr = nil
+			trigger = ""
+			start = n + 1
+		case name == "END" && value == "VTODO" && current != nil:
+			if trigger != "" && currentErr == nil {
+				current.Reminder, currentErr = parseICSTrigger(trigger, triggerAbsolute, current.DueDate)
+			}
+			if currentErr != nil {
+				rowErrors = append(rowErrors, RowError{Row: start, Err: currentErr})
+			} else {
+				tasks = append(tasks, *current)
+			}
+			current = nil
+		case current == nil:
+			continue
+		case name == "BEGIN" && value == "VALARM":
+			inAlarm = true
+		case name == "END" && value == "VALARM":
+			inAlarm = false
+		case inAlarm:
+			// Only the first alarm is kept as the task reminder
+			if name == "TRIGGER" && trigger == "" {
+				trigger = value
+				triggerAbsolute = params["VALUE"] == "DATE-TIME"
+			}
+		default:
+			if err := setICSProperty(current, name, params, value); err != nil && currentErr == nil {
+				currentErr = err
+			}
+		}
+	}
+
+	return tasks, rowErrors, nil
+}
+
+// setICSProperty sets a VTODO property on the task
+func setICSProperty(t *task.Task, name string, params map[string]string, value string) error {
+	switch name {
+	case "UID":
+		t.UID = value
+	case "SUMMARY":
+		t.Title = unescapeICSText(value)
+	case "PRIORITY":
+		p, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid priority: %s", value)
+		}
+		t.Priority = taskPriority(p)
+	case "STATUS":
+		t.Done = value == "COMPLETED"
+	case "COMPLETED":
+		completed, err := parseICSTime(value, params)
+		if err != nil {
+			return err
+		}
+		t.CompletedAt = completed
+	case "CREATED":
+		created, err := parseICSTime(value, params)
+		if err != nil {
+			return err
+		}
+		t.CreatedAt = created
+	case "DUE":
+		due, err := parseICSWallClock(value, params)
+		if err != nil {
+			return err
+		}
+		t.DueDate = &due
+	case "CATEGORIES":
+		for _, tag := range splitICSList(value) {
+			t.Tags = append(t.Tags, unescapeICSText(tag))
+		}
+	}
+	return nil
+}
+
+// icsPriority maps a task priority to the iCalendar scale, 1 is the highest
+func icsPriority(p task.TaskPriority) int {
+	switch p {
+	case task.PriorityUrgent:
+		return 1
+	case task.PriorityHigh:
+		return 2
+	case task.PriorityMedium:
+		return 5
+	case task.PriorityLow:
+		return 8
+	case task.PrioritySomeday:
+		return 9
+	default:
+		return 0
+	}
+}
+
+// taskPriority maps an iCalendar priority to a task priority, 0 is
+// undefined
+func taskPriority(p int) task.TaskPriority {
+	switch {
+	case p == 0:
+		return task.PriorityNone
+	case p == 1:
+		return task.PriorityUrgent
+	case p >= 2 && p <= 4:
+		return task.PriorityHigh
+	case p >= 6 && p <= 8:
+		return task.PriorityLow
+	case p == 9:
+		return task.PrioritySomeday
+	default:
+		return task.DefaultPriority
+	}
+}
+
+// formatICSTime formats a time in UTC
+func formatICSTime(t time.Time) string {
+	return t.UTC().Format(icsDateTime)
+}
+
+// formatICSFloating formats a wall-clock time as a floating time, without
+// a time zone
+func formatICSFloating(t time.Time) string {
+	return t.Format(icsLocalDateTime)
+}
+
+// formatICSDuration formats a duration like -PT2H0M0S or -P1DT0H30M0S
+func formatICSDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+
+	s := sign + "P"
+	if days > 0 {
+		s += fmt.Sprintf("%dD", days)
+	}
+	if d > 0 || days == 0 {
+		s += fmt.Sprintf("T%dH%dM%dS", d/time.Hour, d%time.Hour/time.Minute, d%time.Minute/time.Second)
+	}
+	return s
+}
+
+// parseICSWallClock parses a DUE or TRIGGER time as a wall-clock time, see
+// task.ParseDateTime. Floating times are kept as written, times with a time
+// zone are converted to the local time.
+func parseICSWallClock(value string, params map[string]string) (time.Time, error) {
+	if _, zoned := params["TZID"]; !zoned && !strings.HasSuffix(value, "Z") {
+		for _, layout := range []string{icsLocalDateTime, icsDate} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("invalid date: %s", value)
+	}
+
+	t, err := parseICSTime(value, params)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return task.WallClock(t.In(time.Local)), nil
+}
+
+// parseICSTime parses a DATE-TIME or DATE value. Times without a zone
+// are taken as local times.
+func parseICSTime(value string, params map[string]string) (time.Time, error) {
+	if t, err := time.Parse(icsDateTime, value); err == nil {
+		return t, nil
+	}
+
+	loc := time.Local
+	if tzid, ok := params["TZID"]; ok {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+
+	for _, layout := range []string{icsLocalDateTime, icsDate} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %s", value)
+}
+
+// parseICSTrigger returns the reminder time of an alarm trigger, either an
+// absolute time or a duration relative to the due date
+func parseICSTrigger(trigger string, absolute bool, due *time.Time) (*time.Time, error) {
+	if absolute {
+		t, err := parseICSWallClock(trigger, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &t, nil
+	}
+
+	if due == nil {
+		return nil, fmt.Errorf("relative alarm without due date")
+	}
+	d, err := parseICSDuration(trigger)
+	if err != nil {
+		return nil, err
+	}
+	reminder := due.Add(d)
+	return &reminder, nil
+}
+
+// parseICSDuration parses durations like -PT15M, P1D or -P1DT2H
+func parseICSDuration(s string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid duration: %s", s)
+
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign = -1
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, invalid
+	}
+	s = s[1:]
+
+	units := map[byte]time.Duration{
+		'W': 7 * 24 * time.Hour,
+		'D': 24 * time.Hour,
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+	}
+
+	var total time.Duration
+	number := ""
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == 'T':
+			continue
+		case c >= '0' && c <= '9':
+			number += string(c)
+		default:
+			unit, ok := units[c]
+			if !ok || number == "" {
+				return 0, invalid
+			}
+			n, _ := strconv.Atoi(number)
+			total += time.Duration(n) * unit
+			number = ""
+		}
+	}
+	if number != "" {
+		return 0, invalid
+	}
+
+	return sign * total, nil
+}
+
+// unfoldICSLines reads the content lines joining the folded ones
+func unfoldICSLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// parseICSLine splits a content line into its name, parameters and value
+func parseICSLine(line string) (string, map[string]string, string, error) {
+	// The value starts at the first colon that isn't inside a quoted parameter
+	inQuotes := false
+	sep := -1
+	for i, c := range line {
+		if c == '"' {
+			inQuotes = !inQuotes
+		}
+		if c == ':' && !inQuotes {
+			sep = i
+			break
+		}
+	}
+	if sep < 0 {
+		return "", nil, "", fmt.Errorf("invalid content line: %s", line)
+	}
+
+	parts := strings.Split(line[:sep], ";")
+	params := make(map[string]string)
+	for _, p := range parts[1:] {
+		key, value, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+
+	return strings.ToUpper(parts[0]), params, line[sep+1:], nil
+}
+
+// escapeICSText escapes a TEXT value
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// unescapeICSText reverts escapeICSText
+func unescapeICSText(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
+
+// splitICSList splits a list value on the commas that aren't escaped
+func splitICSList(s string) []string {
+	var items []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, s[start:])
+}
+
+// icsWriter writes content lines folding them at 75 octets
+type icsWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line writes a content line, the first error is kept
+func (iw *icsWriter) line(name, value string) {
+	if iw.err != nil {
+		return
+	}
+
+	line := name + ":" + value
+	for len(line) > icsMaxLineLength {
+		// Don't split a multi-byte character
+		cut := icsMaxLineLength
+		for cut > 0 && !utf8RuneStart(line[cut]) {
+			cut--
+		}
+		if _, iw.err = iw.w.WriteString(line[:cut] + "\r\n"); iw.err != nil {
+			return
+		}
+		line = " " + line[cut:]
+	}
+	_, iw.err = iw.w.WriteString(line + "\r\n")
+}
+
+// utf8RuneStart reports whether the byte starts a UTF-8 character
+func utf8RuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,128 @@
+package formats
+
+import (
+	"bytes"
+	"github.com/kubaliski/task-cli/internal/task"
+	"strings"
+	"testing"
+	"time"
+)
+
+// inZone runs the test with the local time zone set to loc
+func inZone(t *testing.T, loc *time.Location) {
+	t.Helper()
+	local := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = local })
+}
+
+func TestEncodeICSWallClock(t *testing.T) {
+	inZone(t, time.FixedZone("UTC+2", 2*60*60))
+
+	due, _ := task.ParseDateTime("2030-01-10 17:00")
+	reminder, _ := task.ParseDateTime("2030-01-10 15:00")
+	alone, _ := task.ParseDateTime("2030-01-09 09:30")
+
+	tests := []struct {
+		name string
+		task task.Task
+		want []string
+	}{
+		{"due date", task.Task{Title: "a", DueDate: &due}, []string{"DUE:20300110T170000\r\n"}},
+		{"reminder before the due date", task.Task{Title: "b", DueDate: &due, Reminder: &reminder},
+			[]string{"DUE:20300110T170000\r\n", "TRIGGER;RELATED=END:-PT2H0M0S\r\n"}},
+		{"reminder alone", task.Task{Title: "c", Reminder: &alone}, []string{"TRIGGER;VALUE=DATE-TIME:20300109T093000\r\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := EncodeICS(&buf, []task.Task{tt.task}); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("missing %q in:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeICSWallClock(t *testing.T) {
+	inZone(t, time.FixedZone("UTC+2", 2*60*60))
+
+	tests := []struct {
+		name     string
+		lines    string
+		due      string
+		reminder string
+	}{
+		{"floating", "DUE:20300110T170000", "2030-01-10 17:00", ""},
+		{"date", "DUE;VALUE=DATE:20300110", "2030-01-10 00:00", ""},
+		{"UTC, shown in the local time", "DUE:20300110T150000Z", "2030-01-10 17:00", ""},
+		{"time zone", "DUE;TZID=America/New_York:20300110T100000", "2030-01-10 17:00", ""},
+		{"relative alarm", "DUE:20300110T170000\nBEGIN:VALARM\nTRIGGER;RELATED=END:-PT2H0M0S\nEND:VALARM", "2030-01-10 17:00", "2030-01-10 15:00"},
+		{"floating alarm", "BEGIN:VALARM\nTRIGGER;VALUE=DATE-TIME:20300109T093000\nEND:VALARM", "", "2030-01-09 09:30"},
+		{"UTC alarm", "BEGIN:VALARM\nTRIGGER;VALUE=DATE-TIME:20300109T073000Z\nEND:VALARM", "", "2030-01-09 09:30"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ics := "BEGIN:VCALENDAR\nBEGIN:VTODO\nUID:1\nSUMMARY:a\n" + tt.lines + "\nEND:VTODO\nEND:VCALENDAR\n"
+			tasks, rowErrors, err := DecodeICS(strings.NewReader(ics))
+			if err != nil || len(rowErrors) > 0 {
+				t.Fatalf("DecodeICS: %v %v", err, rowErrors)
+			}
+			if len(tasks) != 1 {
+				t.Fatalf("got %d tasks, want 1", len(tasks))
+			}
+			for _, c := range []struct {
+				name string
+				got  *time.Time
+				want string
+			}{{"due date", tasks[0].DueDate, tt.due}, {"reminder", tasks[0].Reminder, tt.reminder}} {
+				if c.want == "" {
+					if c.got != nil {
+						t.Errorf("got %s %v, want none", c.name, c.got)
+					}
+					continue
+				}
+				// Wall-clock times are the ones ParseDateTime returns
+				want, _ := task.ParseDateTime(c.want)
+				if c.got == nil || !c.got.Equal(want) || c.got.Location() != time.UTC {
+					t.Errorf("got %s %v, want %v", c.name, c.got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestICSRoundTrip(t *testing.T) {
+	inZone(t, time.FixedZone("UTC-5", -5*60*60))
+
+	due, _ := task.ParseDateTime("2030-01-10 17:00")
+	reminder, _ := task.ParseDateTime("2030-01-08 16:45")
+	original := task.Task{UID: "round-trip", Title: "Round, trip; done", Done: true,
+		CompletedAt: time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC), Priority: task.PriorityHigh,
+		Tags: []string{"a", "b"}, DueDate: &due, Reminder: &reminder}
+
+	var buf bytes.Buffer
+	if err := EncodeICS(&buf, []task.Task{original}); err != nil {
+		t.Fatal(err)
+	}
+	tasks, rowErrors, err := DecodeICS(&buf)
+	if err != nil || len(rowErrors) > 0 || len(tasks) != 1 {
+		t.Fatalf("DecodeICS: %v %v %v", err, rowErrors, tasks)
+	}
+
+	got := tasks[0]
+	if got.UID != original.UID || got.Title != original.Title || got.Done != original.Done ||
+		got.Priority != original.Priority || strings.Join(got.Tags, ",") != "a,b" {
+		t.Errorf("got %+v, want %+v", got, original)
+	}
+	if !got.CompletedAt.Equal(original.CompletedAt) {
+		t.Errorf("got completion %v, want %v", got.CompletedAt, original.CompletedAt)
+	}
+	if !got.DueDate.Equal(due) || !got.Reminder.Equal(reminder) {
+		t.Errorf("got due %v and reminder %v, want %v and %v", got.DueDate, got.Reminder, due, reminder)
+	}
+}
@@ -0,0 +1,124 @@
+package formats
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Markdown groupings
+const (
+	GroupByPriority = "priority"
+	GroupByTag      = "tag"
+	GroupByNone     = "none"
+)
+
+// markdownGroup is a section of the checklist
+type markdownGroup struct {
+	title string
+	tasks []task.Task
+}
+
+// MarkdownEncoder returns an encoder of GitHub-style checklists grouped by
+// priority, by tag or not grouped at all
+func MarkdownEncoder(groupBy string) (Encoder, error) {
+	switch groupBy {
+	case GroupByPriority, GroupByTag, GroupByNone:
+	default:
+		return nil, fmt.Errorf("unknown grouping: %s (expected priority, tag or none)", groupBy)
+	}
+
+	return func(w io.Writer, tasks []task.Task) error {
+		bw := bufio.NewWriter(w)
+		for i, group := range markdownGroups(tasks, groupBy) {
+			if i > 0 {
+				bw.WriteString("\n")
+			}
+			if group.title != "" {
+				fmt.Fprintf(bw, "## %s\n\n", group.title)
+			}
+			for _, t := range group.tasks {
+				bw.WriteString(formatMarkdownItem(t) + "\n")
+			}
+		}
+		return bw.Flush()
+	}, nil
+}
+
+// markdownGroups splits the tasks in sections keeping their order
+func markdownGroups(tasks []task.Task, groupBy string) []markdownGroup {
+	switch groupBy {
+	case GroupByPriority:
+		var groups []markdownGroup
+		for i := len(task.Priorities) - 1; i >= 0; i-- {
+			p := task.Priorities[i]
+			group := markdownGroup{title: p.String()}
+			for _, t := range tasks {
+				if t.Priority == p {
+					group.tasks = append(group.tasks, t)
+				}
+			}
+			if len(group.tasks) > 0 {
+				groups = append(groups, group)
+			}
+		}
+		return groups
+
+	case GroupByTag:
+		// A task is listed under each of its tags
+		byTag := make(map[string][]task.Task)
+		var untagged []task.Task
+		for _, t := range tasks {
+			if len(t.Tags) == 0 {
+				untagged = append(untagged, t)
+			}
+			for _, tag := range t.Tags {
+				byTag[tag] = append(byTag[tag], t)
+			}
+		}
+
+		tags := make([]string, 0, len(byTag))
+		for tag := range byTag {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+
+		var groups []markdownGroup
+		for _, tag := range tags {
+			groups = append(groups, markdownGroup{title: "#" + tag, tasks: byTag[tag]})
+		}
+		if len(untagged) > 0 {
+			groups = append(groups, markdownGroup{title: "Untagged", tasks: untagged})
+		}
+		return groups
+
+	default:
+		return []markdownGroup{{tasks: tasks}}
+	}
+}
+
+// formatMarkdownItem formats a task as a checklist item
+func formatMarkdownItem(t task.Task) string {
+	check := " "
+	if t.Done {
+		check = "x"
+	}
+
+	item := fmt.Sprintf("- [%s] %s", check, escapeMarkdown(t.Title))
+	if t.DueDate != nil {
+		item += fmt.Sprintf(" (due %s)", task.FormatDateTime(t.DueDate))
+	}
+	if len(t.Tags) > 0 {
+		item += " `#" + strings.Join(t.Tags, "` `#") + "`"
+	}
+	return item
+}
+
+// escapeMarkdown escapes the characters that would change the formatting
+// of a list item
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`).Replace(s)
+}
@@ -0,0 +1,117 @@
+package formats
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"io"
+	"strings"
+	"time"
+)
+
+// taskwarriorDate is the date format of "task export"
+const taskwarriorDate = "20060102T150405Z"
+
+// taskwarriorTask is an entry of the "task export" JSON output
+type taskwarriorTask struct {
+	UUID        string   `json:"uuid"`
+	Description string   `json:"description"`
+	Status      string   `json:"status"`
+	Entry       string   `json:"entry"`
+	End         string   `json:"end"`
+	Due         string   `json:"due"`
+	Priority    string   `json:"priority"`
+	Project     string   `json:"project"`
+	Tags        []string `json:"tags"`
+}
+
+// DecodeTaskwarrior reads the JSON produced by Taskwarrior's "task export".
+// Deleted and recurring template tasks are skipped, the project becomes a tag.
+func DecodeTaskwarrior(r io.Reader) ([]task.Task, []RowError, error) {
+	var entries []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, nil, fmt.Errorf("invalid Taskwarrior export: %v", err)
+	}
+
+	var tasks []task.Task
+	var rowErrors []RowError
+	for i, raw := range entries {
+		var tw taskwarriorTask
+		if err := json.Unmarshal(raw, &tw); err != nil {
+			rowErrors = append(rowErrors, RowError{Row: i + 1, Err: err})
+			continue
+		}
+
+		t, err := tw.toTask()
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: i + 1, Err: err})
+			continue
+		}
+		tasks = append(tasks, t)
+	}
+
+	return tasks, rowErrors, nil
+}
+
+// toTask converts a Taskwarrior entry
+func (tw taskwarriorTask) toTask() (task.Task, error) {
+	switch tw.Status {
+	case "deleted":
+		return task.Task{}, fmt.Errorf("%q is deleted in Taskwarrior", tw.Description)
+	case "recurring":
+		return task.Task{}, fmt.Errorf("%q is a recurring template", tw.Description)
+	}
+
+	t := task.Task{
+		UID:   tw.UUID,
+		Title: tw.Description,
+		Done:  tw.Status == "completed",
+		Tags:  tw.Tags,
+	}
+	if tw.Project != "" {
+		t.Tags = append(t.Tags, tw.Project)
+	}
+
+	// Taskwarrior tasks have no priority unless one is set
+	switch strings.ToUpper(tw.Priority) {
+	case "":
+		t.Priority = task.PriorityNone
+	case "H":
+		t.Priority = task.PriorityHigh
+	case "L":
+		t.Priority = task.PriorityLow
+	default:
+		t.Priority = task.PriorityMedium
+	}
+
+	var err error
+	if t.CreatedAt, err = parseTaskwarriorDate(tw.Entry); err != nil {
+		return task.Task{}, err
+	}
+	if t.CompletedAt, err = parseTaskwarriorDate(tw.End); err != nil {
+		return task.Task{}, err
+	}
+	if tw.Due != "" {
+		due, err := parseTaskwarriorDate(tw.Due)
+		if err != nil {
+			return task.Task{}, err
+		}
+		// Taskwarrior dates are in UTC, due dates are wall-clock times
+		due = task.WallClock(due.In(time.Local))
+		t.DueDate = &due
+	}
+
+	return t, nil
+}
+
+// parseTaskwarriorDate parses a Taskwarrior date, empty dates are zero
+func parseTaskwarriorDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(taskwarriorDate, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date: %s", s)
+	}
+	return t, nil
+}
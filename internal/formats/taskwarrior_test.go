@@ -0,0 +1,64 @@
+package formats
+
+import (
+	"github.com/kubaliski/task-cli/internal/task"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeTaskwarrior(t *testing.T) {
+	inZone(t, time.FixedZone("UTC+2", 2*60*60))
+
+	tests := []struct {
+		name     string
+		entry    string
+		wantErr  bool
+		done     bool
+		priority task.TaskPriority
+		tags     string
+		due      string
+	}{
+		{"pending", `{"uuid":"u","description":"a","status":"pending","entry":"20240101T100000Z"}`, false, false, task.PriorityNone, "", ""},
+		{"completed with priority", `{"uuid":"u","description":"a","status":"completed","priority":"H","entry":"20240101T100000Z","end":"20240102T100000Z"}`, false, true, task.PriorityHigh, "", ""},
+		{"low priority", `{"uuid":"u","description":"a","status":"pending","priority":"l"}`, false, false, task.PriorityLow, "", ""},
+		{"project as tag", `{"uuid":"u","description":"a","status":"pending","tags":["x"],"project":"work"}`, false, false, task.PriorityNone, "x,work", ""},
+		// Taskwarrior dates are in UTC, shown in the local time
+		{"due date", `{"uuid":"u","description":"a","status":"pending","due":"20300110T150000Z"}`, false, false, task.PriorityNone, "", "2030-01-10 17:00"},
+		{"deleted", `{"uuid":"u","description":"a","status":"deleted"}`, true, false, 0, "", ""},
+		{"recurring", `{"uuid":"u","description":"a","status":"recurring"}`, true, false, 0, "", ""},
+		{"invalid date", `{"uuid":"u","description":"a","status":"pending","due":"tomorrow"}`, true, false, 0, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, rowErrors, err := DecodeTaskwarrior(strings.NewReader("[" + tt.entry + "]"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				if len(rowErrors) != 1 || len(tasks) != 0 {
+					t.Errorf("got tasks %v and errors %v, want an error", tasks, rowErrors)
+				}
+				return
+			}
+			if len(rowErrors) > 0 || len(tasks) != 1 {
+				t.Fatalf("got tasks %v and errors %v, want a task", tasks, rowErrors)
+			}
+
+			got := tasks[0]
+			if got.UID != "u" || got.Done != tt.done || got.Priority != tt.priority || strings.Join(got.Tags, ",") != tt.tags {
+				t.Errorf("got %+v", got)
+			}
+			if tt.due == "" {
+				if got.DueDate != nil {
+					t.Errorf("got due date %v, want none", got.DueDate)
+				}
+				return
+			}
+			want, _ := task.ParseDateTime(tt.due)
+			if got.DueDate == nil || !got.DueDate.Equal(want) {
+				t.Errorf("got due date %v, want %v", got.DueDate, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package formats
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// todoTxtDate is the date format of todo.txt
+const todoTxtDate = "2006-01-02"
+
+// todoTxtPriority matches a priority like "(A) "
+var todoTxtPriority = regexp.MustCompile(`^\(([A-Z])\) `)
+
+// DecodeTodoTxt reads a todo.txt file. Priorities (A), (B) and (C) map to
+// high, medium and low, the lower letters to someday, +projects and
+// @contexts become tags and due: sets the due date.
+func DecodeTodoTxt(r io.Reader) ([]task.Task, []RowError, error) {
+	var tasks []task.Task
+	var rowErrors []RowError
+
+	scanner := bufio.NewScanner(r)
+	row := 0
+	for scanner.Scan() {
+		row++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		t, err := parseTodoTxtLine(line)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: row, Err: err})
+			continue
+		}
+		tasks = append(tasks, t)
+	}
+
+	return tasks, rowErrors, scanner.Err()
+}
+
+// EncodeTodoTxt writes the tasks as a todo.txt file. Tags are written as
+// +projects and completed tasks keep their priority as pri:
+func EncodeTodoTxt(w io.Writer, tasks []task.Task) error {
+	bw := bufio.NewWriter(w)
+	for _, t := range tasks {
+		if _, err := bw.WriteString(formatTodoTxtLine(t) + "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// formatTodoTxtLine formats a task as a todo.txt line
+func formatTodoTxtLine(t task.Task) string {
+	var parts []string
+
+	letter := priorityToTodoTxt(t.Priority)
+	if t.Done {
+		parts = append(parts, "x", t.CompletedAt.Format(todoTxtDate))
+	} else if letter != "" {
+		parts = append(parts, "("+letter+")")
+	}
+
+	if !t.CreatedAt.IsZero() {
+		parts = append(parts, t.CreatedAt.Format(todoTxtDate))
+	}
+
+	parts = append(parts, strings.Join(strings.Fields(t.Title), " "))
+
+	for _, tag := range t.Tags {
+		parts = append(parts, "+"+tag)
+	}
+	if t.DueDate != nil {
+		parts = append(parts, "due:"+t.DueDate.Format(todoTxtDate))
+	}
+	if t.Done && letter != "" {
+		parts = append(parts, "pri:"+letter)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// priorityToTodoTxt maps a task priority to a todo.txt priority letter
+func priorityToTodoTxt(p task.TaskPriority) string {
+	switch p {
+	case task.PriorityUrgent, task.PriorityHigh:
+		return "A"
+	case task.PriorityMedium:
+		return "B"
+	case task.PriorityLow:
+		return "C"
+	case task.PrioritySomeday:
+		return "D"
+	default:
+		return ""
+	}
+}
+
+// parseTodoTxtLine parses a todo.txt task
+func parseTodoTxtLine(line string) (task.Task, error) {
+	t := task.Task{Priority: task.DefaultPriority}
+
+	// Completion mark and date
+	if strings.HasPrefix(line, "x ") {
+		t.Done = true
+		line = strings.TrimPrefix(line, "x ")
+		if date, rest, ok := cutTodoTxtDate(line); ok {
+			t.CompletedAt = date
+			line = rest
+		}
+	}
+
+	if m := todoTxtPriority.FindStringSubmatch(line); m != nil {
+		t.Priority = todoTxtToPriority(m[1])
+		line = line[len(m[0]):]
+	}
+
+	// Creation date
+	if date, rest, ok := cutTodoTxtDate(line); ok {
+		t.CreatedAt = date
+		line = rest
+	}
+
+	var words []string
+	for _, word := range strings.Fields(line) {
+		key, value, isTag := strings.Cut(word, ":")
+		switch {
+		case (strings.HasPrefix(word, "+") || strings.HasPrefix(word, "@")) && len(word) > 1:
+			t.Tags = append(t.Tags, word[1:])
+		case isTag && key == "due":
+			due, err := time.Parse(todoTxtDate, value)
+			if err != nil {
+				return task.Task{}, fmt.Errorf("invalid due date: %s", value)
+			}
+			t.DueDate = &due
+		case isTag && key == "pri" && len(value) == 1:
+			// Completed tasks keep their priority as pri:A
+			t.Priority = todoTxtToPriority(strings.ToUpper(value))
+		default:
+			words = append(words, word)
+		}
+	}
+
+	t.Title = strings.Join(words, " ")
+	if t.Title == "" {
+		return task.Task{}, fmt.Errorf("task without description")
+	}
+	return t, nil
+}
+
+// cutTodoTxtDate removes a leading date from a line
+func cutTodoTxtDate(line string) (time.Time, string, bool) {
+	word, rest, _ := strings.Cut(line, " ")
+	date, err := time.Parse(todoTxtDate, word)
+	if err != nil {
+		return time.Time{}, line, false
+	}
+	return date, rest, true
+}
+
+// todoTxtToPriority maps a todo.txt priority letter to a task priority
+func todoTxtToPriority(letter string) task.TaskPriority {
+	switch letter {
+	case "A":
+		return task.PriorityHigh
+	case "B":
+		return task.PriorityMedium
+	case "C":
+		return task.PriorityLow
+	default:
+		return task.PrioritySomeday
+	}
+}
@@ -0,0 +1,374 @@
+// Package gitsync keeps the data directory in a git repository and
+// synchronizes it with a remote, merging concurrent edits task by task
+package gitsync
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// remoteName is the name of the remote used for syncing
+	remoteName = "origin"
+	// defaultBranch is the branch created by Init
+	defaultBranch = "main"
+)
+
+// ErrNotInitialized is returned when the data directory is not a git repository
+var ErrNotInitialized = errors.New("sync is not initialized, run 'task sync init <remote>'")
+
+// Repo is a data directory kept under git
+type Repo struct {
+	dir string
+	env []string
+}
+
+// Result describes what a sync did
+type Result struct {
+	Pulled    bool
+	Merged    bool
+	Pushed    bool
+	Conflicts []task.MergeConflict
+}
+
+// Open returns the repository for the given data directory
+func Open(dir string) *Repo {
+	return &Repo{dir: dir}
+}
+
+// Enabled reports whether the data directory is a git repository
+func (r *Repo) Enabled() bool {
+	info, err := os.Stat(filepath.Join(r.dir, ".git"))
+	return err == nil && info.IsDir()
+}
+
+// Init turns the data directory into a git repository, sets the remote and
+// commits the current data
+func (r *Repo) Init(remote string) error {
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return err
+	}
+
+	if !r.Enabled() {
+		if _, err := r.git("init", "-q"); err != nil {
+			return err
+		}
+		if _, err := r.git("symbolic-ref", "HEAD", "refs/heads/"+defaultBranch); err != nil {
+			return err
+		}
+	}
+
+	if remote != "" {
+		if _, err := r.git("remote", "get-url", remoteName); err == nil {
+			_, err = r.git("remote", "set-url", remoteName, remote)
+			if err != nil {
+				return err
+			}
+		} else if _, err := r.git("remote", "add", remoteName, remote); err != nil {
+			return err
+		}
+	}
+
+	_, err := r.Commit("Initialize task data")
+	return err
+}
+
+// Commit records the changes of the task files. The rest of the data
+// directory, like the configuration and the hook logs, is left out. It
+// reports whether there was anything to commit.
+func (r *Repo) Commit(message string) (bool, error) {
+	if !r.Enabled() {
+		return false, ErrNotInitialized
+	}
+
+	staged, err := r.stageTaskFiles()
+	if err != nil || !staged {
+		return false, err
+	}
+	if _, err := r.git("commit", "-q", "-m", message); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// stageTaskFiles stages the task files that are new, changed or removed
+// since the last commit. It reports whether any is staged.
+func (r *Repo) stageTaskFiles() (bool, error) {
+	out, err := r.git(append([]string{"ls-files", "--modified", "--deleted", "--others", "--"}, task.TaskFiles...)...)
+	if err != nil {
+		return false, err
+	}
+
+	// Only the listed paths are added, git refuses the ones that don't exist
+	var changed []string
+	for _, name := range strings.Split(out, "\n") {
+		if name != "" {
+			changed = append(changed, name)
+		}
+	}
+	if len(changed) > 0 {
+		if _, err := r.git(append([]string{"add", "-A", "--"}, changed...)...); err != nil {
+			return false, err
+		}
+	}
+
+	staged, err := r.git(append([]string{"diff", "--cached", "--name-only", "--"}, task.TaskFiles...)...)
+	return staged != "", err
+}
+
+// Sync commits local changes, pulls the remote changes merging them task by
+// task, and pushes the result
+func (r *Repo) Sync() (Result, error) {
+	var result Result
+
+	if !r.Enabled() {
+		return result, ErrNotInitialized
+	}
+	if _, err := r.git("remote", "get-url", remoteName); err != nil {
+		return result, fmt.Errorf("no remote configured, run 'task sync init <remote>'")
+	}
+
+	if _, err := r.Commit("Save local changes"); err != nil {
+		return result, err
+	}
+
+	branch, err := r.git("symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return result, err
+	}
+
+	heads, err := r.git("ls-remote", "--heads", remoteName, branch)
+	if err != nil {
+		return result, err
+	}
+
+	if heads != "" {
+		if _, err := r.git("fetch", "-q", remoteName, branch); err != nil {
+			return result, err
+		}
+		remoteRef := "refs/remotes/" + remoteName + "/" + branch
+
+		switch {
+		case !r.hasCommits():
+			// Nothing saved locally yet, start from the remote data
+			if _, err := r.git("merge", "-q", "--ff-only", remoteRef); err != nil {
+				return result, err
+			}
+			result.Pulled = true
+		case r.isAncestor(remoteRef, "HEAD"):
+			// Nothing new on the remote
+		case r.isAncestor("HEAD", remoteRef):
+			if _, err := r.git("merge", "-q", "--ff-only", remoteRef); err != nil {
+				return result, err
+			}
+			result.Pulled = true
+		default:
+			conflicts, err := r.merge(remoteRef)
+			if err != nil {
+				return result, err
+			}
+			result.Pulled = true
+			result.Merged = true
+			result.Conflicts = conflicts
+		}
+	}
+
+	if r.hasCommits() && !r.isAncestor("HEAD", "refs/remotes/"+remoteName+"/"+branch) {
+		if _, err := r.git("push", "-q", "-u", remoteName, "HEAD:refs/heads/"+branch); err != nil {
+			return result, err
+		}
+		result.Pushed = true
+	}
+
+	return result, nil
+}
+
+// merge merges the remote branch into the current one, combining the task
+// files task by task and field by field. Any other file keeps its local
+// version.
+func (r *Repo) merge(remoteRef string) ([]task.MergeConflict, error) {
+	base, err := r.git("merge-base", "HEAD", remoteRef)
+	if err != nil {
+		// Unrelated histories, for example when the data existed on both
+		// machines before syncing was set up
+		base = ""
+	}
+
+	// Without a common ancestor the tasks are merged by modification time
+	hasBase := base != ""
+
+	merged := make(map[string][]task.Task)
+	var conflicts []task.MergeConflict
+	for _, name := range task.TaskFiles {
+		var baseTasks []task.Task
+		if hasBase {
+			baseTasks, err = r.tasksAt(base, name)
+			if err != nil {
+				return nil, err
+			}
+			if baseTasks == nil {
+				baseTasks = []task.Task{}
+			}
+		}
+		localTasks, err := r.tasksAt("HEAD", name)
+		if err != nil {
+			return nil, err
+		}
+		remoteTasks, err := r.tasksAt(remoteRef, name)
+		if err != nil {
+			return nil, err
+		}
+
+		tasks, result := task.MergeTasks(baseTasks, localTasks, remoteTasks)
+		merged[name] = tasks
+		conflicts = append(conflicts, result.Conflicts...)
+	}
+
+	// A task archived on one side and changed on the other ends up in both
+	// files, the archived version is kept. IDs used in both files are fixed
+	// when the tasks are loaded.
+	archived := make(map[string]bool)
+	for _, t := range merged[task.TaskFiles[1]] {
+		archived[t.UID] = true
+	}
+	var active []task.Task
+	for _, t := range merged[task.TaskFiles[0]] {
+		if t.UID != "" && archived[t.UID] {
+			conflicts = append(conflicts, task.MergeConflict{ID: t.ID, Title: t.Title, Resolution: "archived on one side, kept archived"})
+			continue
+		}
+		active = append(active, t)
+	}
+	merged[task.TaskFiles[0]] = active
+
+	args := []string{"merge", "-q", "--no-commit", "--no-ff", "-s", "ours"}
+	if !hasBase {
+		args = append(args, "--allow-unrelated-histories")
+	}
+	if _, err := r.git(append(args, remoteRef)...); err != nil {
+		return nil, err
+	}
+
+	for _, name := range task.TaskFiles {
+		if err := r.writeTasks(name, merged[name]); err != nil {
+			r.git("merge", "--abort")
+			return nil, err
+		}
+	}
+
+	// Only the task files are staged, the merge keeps the local version of
+	// everything else
+	if _, err := r.stageTaskFiles(); err != nil {
+		return nil, err
+	}
+	message := "Merge remote tasks"
+	if len(conflicts) > 0 {
+		message = fmt.Sprintf("Merge remote tasks (%d conflict(s))", len(conflicts))
+	}
+	if _, err := r.git("commit", "-q", "-m", message); err != nil {
+		return nil, err
+	}
+
+	return conflicts, nil
+}
+
+// tasksAt reads a task file as it is in the given revision. A missing file
+// or revision is an empty list.
+func (r *Repo) tasksAt(rev, name string) ([]task.Task, error) {
+	if rev == "" {
+		return nil, nil
+	}
+	if _, err := r.git("cat-file", "-e", rev+":"+name); err != nil {
+		return nil, nil
+	}
+
+	data, err := r.git("show", rev+":"+name)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []task.Task
+	if err := json.Unmarshal([]byte(data), &tasks); err != nil {
+		return nil, fmt.Errorf("invalid %s in %s: %v", name, rev, err)
+	}
+	return tasks, nil
+}
+
+// writeTasks writes a merged task file. Empty lists are only written when
+// the file already exists.
+func (r *Repo) writeTasks(name string, tasks []task.Task) error {
+	path := filepath.Join(r.dir, name)
+	if _, err := os.Stat(path); len(tasks) == 0 && os.IsNotExist(err) {
+		return nil
+	}
+	if tasks == nil {
+		tasks = []task.Task{}
+	}
+
+	data, err := json.MarshalIndent(tasks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// hasCommits reports whether the current branch has any commit
+func (r *Repo) hasCommits() bool {
+	_, err := r.git("rev-parse", "--verify", "-q", "HEAD")
+	return err == nil
+}
+
+// isAncestor reports whether the revision a is an ancestor of b
+func (r *Repo) isAncestor(a, b string) bool {
+	_, err := r.git("merge-base", "--is-ancestor", a, b)
+	return err == nil
+}
+
+// git runs a git command in the data directory and returns its trimmed output
+func (r *Repo) git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	if r.env == nil {
+		r.env = append(os.Environ(), r.identityEnv()...)
+	}
+	cmd.Env = r.env
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// identityEnv provides a commit identity when git has none configured
+func (r *Repo) identityEnv() []string {
+	cmd := exec.Command("git", "config", "user.email")
+	cmd.Dir = r.dir
+	if out, err := cmd.Output(); err == nil && len(bytes.TrimSpace(out)) > 0 {
+		return nil
+	}
+
+	name := os.Getenv("USER")
+	if name == "" {
+		name = "task-cli"
+	}
+	email := name + "@task-cli"
+	return []string{
+		"GIT_AUTHOR_NAME=" + name,
+		"GIT_AUTHOR_EMAIL=" + email,
+		"GIT_COMMITTER_NAME=" + name,
+		"GIT_COMMITTER_EMAIL=" + email,
+	}
+}
@@ -0,0 +1,351 @@
+// Package hooks runs the commands and webhooks configured for task events,
+// and records every run in a log
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/config"
+	"github.com/kubaliski/task-cli/internal/task"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// logFileName is the log of the hook runs in the data directory
+	logFileName = "hooks.log"
+	// overdueFileName keeps the overdue tasks already notified
+	overdueFileName = "hooks-overdue.json"
+	// retryDelay is the wait before the first retry, it grows with every one
+	retryDelay = time.Second
+	// maxOutput is the length of the command output kept in the log
+	maxOutput = 200
+	// waitDelay is how long a timed out command can keep its output open,
+	// through the processes it started, before it's abandoned
+	waitDelay = time.Second
+	// queueSize is the number of runs a hook can have waiting
+	queueSize = 64
+)
+
+// Payload is the JSON sent to the hooks, on the standard input of commands
+// and as the body of the POST requests
+type Payload struct {
+	Event  string    `json:"event"`
+	Action string    `json:"action"`
+	At     time.Time `json:"at"`
+	Task   task.Task `json:"task"`
+}
+
+// Runner fires the hooks of a data directory. Hooks run in the background,
+// each one in the order of its events, Wait waits for them to finish.
+type Runner struct {
+	dir    string
+	hooks  []config.Hook
+	queues []chan Payload
+	client *http.Client
+
+	wg    sync.WaitGroup
+	logMu sync.Mutex
+	// overdueMu serializes the overdue checks
+	overdueMu sync.Mutex
+}
+
+// New creates a runner for the hooks of a data directory
+func New(dataDir string, hooks []config.Hook) *Runner {
+	r := &Runner{
+		dir:    dataDir,
+		hooks:  hooks,
+		queues: make([]chan Payload, len(hooks)),
+		client: &http.Client{},
+	}
+	for i, h := range hooks {
+		r.queues[i] = make(chan Payload, queueSize)
+		go r.work(h, r.queues[i])
+	}
+	return r
+}
+
+// LogPath returns the path of the hook log of a data directory
+func LogPath(dataDir string) string {
+	return filepath.Join(dataDir, logFileName)
+}
+
+// EventOf returns the hook event of the action of a task manager event,
+// the changes that don't add, complete, delete or purge a task are updates.
+// Purging is its own event, the tasks purged from the trash were already
+// deleted.
+func EventOf(action string) string {
+	switch action {
+	case task.ActionCreated, task.ActionImported:
+		return config.EventAdd
+	case task.ActionCompleted:
+		return config.EventComplete
+	case task.ActionDeleted:
+		return config.EventDelete
+	case task.ActionPurged:
+		return config.EventPurge
+	default:
+		return config.EventUpdate
+	}
+}
+
+// Attach fires the hooks on the changes made through the task manager. The
+// returned function detaches the runner.
+func (r *Runner) Attach(tm task.ITaskManager) func() {
+	if len(r.hooks) == 0 {
+		return func() {}
+	}
+	return tm.Subscribe(func(e task.Event) {
+		r.Fire(EventOf(e.Action), e.Action, e.Task)
+	})
+}
+
+// Fire runs in the background the hooks of an event. It never waits: when
+// a hook already has queueSize runs waiting, the event is dropped for it
+// and the drop is logged.
+func (r *Runner) Fire(event, action string, t task.Task) {
+	payload := Payload{Event: event, Action: action, At: time.Now(), Task: t}
+	for i, h := range r.hooks {
+		if !h.Fires(event) {
+			continue
+		}
+		r.wg.Add(1)
+		select {
+		case r.queues[i] <- payload:
+		default:
+			r.wg.Done()
+			r.writeLog(h, payload, fmt.Sprintf("dropped, %d runs already waiting", queueSize))
+		}
+	}
+}
+
+// work runs a hook for every payload of its queue
+func (r *Runner) work(h config.Hook, queue <-chan Payload) {
+	for payload := range queue {
+		r.run(h, payload)
+		r.wg.Done()
+	}
+}
+
+// Test runs the hooks of an event for a task and waits for them. It returns
+// the number of hooks run.
+func (r *Runner) Test(event string, t task.Task) int {
+	hooks := r.hooksFor(event)
+	r.Fire(event, "test", t)
+	r.Wait()
+	return len(hooks)
+}
+
+// Wait waits for the running hooks to finish
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+// hooksFor returns the hooks fired by an event
+func (r *Runner) hooksFor(event string) []config.Hook {
+	var hooks []config.Hook
+	for _, h := range r.hooks {
+		if h.Fires(event) {
+			hooks = append(hooks, h)
+		}
+	}
+	return hooks
+}
+
+// run runs a hook, retrying it when it fails, and logs the result
+func (r *Runner) run(h config.Hook, payload Payload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		r.log(h, payload, 0, 0, err)
+		return
+	}
+
+	start := time.Now()
+	attempts := 0
+	for {
+		attempts++
+		err = r.attempt(h, payload, body)
+		if err == nil || attempts > h.Retries {
+			break
+		}
+		time.Sleep(retryDelay * time.Duration(attempts))
+	}
+	r.log(h, payload, attempts, time.Since(start), err)
+}
+
+// attempt runs a hook once
+func (r *Runner) attempt(h config.Hook, payload Payload, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.TimeoutOrDefault())
+	defer cancel()
+
+	if h.URL != "" {
+		return r.post(ctx, h.URL, body)
+	}
+	return runCommand(ctx, h.Command, payload, body)
+}
+
+// post sends the payload to a URL, any status other than 2xx is an error
+func (r *Runner) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
+
+// runCommand runs a shell command with the payload on its standard input
+// and the event in its environment
+func runCommand(ctx context.Context, command string, payload Payload, body []byte) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.WaitDelay = waitDelay
+	cmd.Env = append(os.Environ(),
+		"TASK_EVENT="+payload.Event,
+		"TASK_ACTION="+payload.Action,
+		fmt.Sprintf("TASK_ID=%d", payload.Task.ID),
+		"TASK_TITLE="+payload.Task.Title,
+	)
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out")
+	}
+	if err != nil {
+		out := strings.TrimSpace(string(output))
+		if len(out) > maxOutput {
+			out = out[:maxOutput] + "..."
+		}
+		if out != "" {
+			return fmt.Errorf("%v: %s", err, out)
+		}
+		return err
+	}
+	return nil
+}
+
+// log appends the result of a hook run to the hook log
+func (r *Runner) log(h config.Hook, payload Payload, attempts int, elapsed time.Duration, err error) {
+	result := fmt.Sprintf("ok in %s", elapsed.Round(time.Millisecond))
+	if err != nil {
+		result = fmt.Sprintf("failed after %d attempt(s): %v", attempts, err)
+	}
+	r.writeLog(h, payload, result)
+}
+
+// writeLog appends a line about a hook and its payload to the hook log
+func (r *Runner) writeLog(h config.Hook, payload Payload, result string) {
+	line := fmt.Sprintf("%s %s task %d (%s) %s: %s\n",
+		time.Now().Format("2006-01-02 15:04:05"), payload.Event, payload.Task.ID, payload.Task.Title, h.Target(), result)
+
+	r.logMu.Lock()
+	defer r.logMu.Unlock()
+
+	f, ferr := os.OpenFile(LogPath(r.dir), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if ferr != nil {
+		return
+	}
+	defer f.Close()
+	f.WriteString(line)
+}
+
+// CheckOverdue fires the overdue hooks of the tasks that became overdue
+// since the last check. A task fires them again when its due date changes.
+func (r *Runner) CheckOverdue(tm task.ITaskManager) error {
+	if len(r.hooksFor(config.EventOverdue)) == 0 {
+		return nil
+	}
+
+	r.overdueMu.Lock()
+	defer r.overdueMu.Unlock()
+
+	notified, err := r.loadNotified()
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]time.Time)
+	changed := false
+	for _, t := range tm.GetTasksByTimeStatus(task.TimeStatusOverdue) {
+		if t.Done || t.DeletedAt != nil || t.DueDate == nil {
+			continue
+		}
+		current[t.UID] = *t.DueDate
+		if due, ok := notified[t.UID]; ok && due.Equal(*t.DueDate) {
+			continue
+		}
+		r.Fire(config.EventOverdue, "overdue", t)
+		changed = true
+	}
+
+	// Tasks no longer overdue are forgotten so they notify again
+	if changed || len(current) != len(notified) {
+		return r.saveNotified(current)
+	}
+	return nil
+}
+
+// WatchOverdue checks the overdue tasks every interval until the context
+// is done
+func (r *Runner) WatchOverdue(ctx context.Context, tm task.ITaskManager, interval time.Duration) {
+	if len(r.hooksFor(config.EventOverdue)) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.CheckOverdue(tm)
+		}
+	}
+}
+
+// loadNotified reads the due dates of the overdue tasks already notified,
+// by task UID
+func (r *Runner) loadNotified() (map[string]time.Time, error) {
+	notified := make(map[string]time.Time)
+	data, err := os.ReadFile(filepath.Join(r.dir, overdueFileName))
+	if os.IsNotExist(err) {
+		return notified, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &notified); err != nil {
+		return nil, err
+	}
+	return notified, nil
+}
+
+// saveNotified writes the overdue tasks already notified
+func (r *Runner) saveNotified(notified map[string]time.Time) error {
+	data, err := json.MarshalIndent(notified, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(r.dir, overdueFileName), data, 0644)
+}
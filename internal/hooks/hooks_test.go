@@ -0,0 +1,76 @@
+package hooks
+
+import (
+	"github.com/kubaliski/task-cli/internal/config"
+	"github.com/kubaliski/task-cli/internal/task"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventOf(t *testing.T) {
+	tests := []struct {
+		action string
+		want   string
+	}{
+		{task.ActionCreated, config.EventAdd},
+		{task.ActionImported, config.EventAdd},
+		{task.ActionCompleted, config.EventComplete},
+		{task.ActionDeleted, config.EventDelete},
+		// Purged tasks were already deleted, they don't fire delete again
+		{task.ActionPurged, config.EventPurge},
+		{task.ActionReopened, config.EventUpdate},
+		{task.ActionUpdated, config.EventUpdate},
+		{task.ActionMerged, config.EventUpdate},
+	}
+	for _, tt := range tests {
+		if got := EventOf(tt.action); got != tt.want {
+			t.Errorf("EventOf(%s) = %s, want %s", tt.action, got, tt.want)
+		}
+	}
+}
+
+func TestFireDropsWhenQueueIsFull(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	r := New(dir, []config.Hook{{Events: []string{config.EventAdd}, URL: srv.URL}})
+
+	// The hook is stuck on its first run, the rest wait in its queue
+	const extra = 10
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < queueSize+1+extra; i++ {
+			r.Fire(config.EventAdd, task.ActionCreated, task.Task{ID: i})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Fire blocked on a full queue")
+	}
+
+	close(release)
+	r.Wait()
+
+	data, err := os.ReadFile(LogPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first event may still be in the queue when the others are fired
+	dropped := strings.Count(string(data), "dropped")
+	if dropped < extra || dropped > extra+1 {
+		t.Errorf("got %d dropped events, want %d:\n%s", dropped, extra, data)
+	}
+	if ok := strings.Count(string(data), ": ok in"); ok+dropped != queueSize+1+extra {
+		t.Errorf("got %d runs and %d dropped events for %d events", ok, dropped, queueSize+1+extra)
+	}
+}
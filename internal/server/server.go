@@ -0,0 +1,525 @@
+// Package server exposes the tasks through a JSON REST API
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kubaliski/task-cli/internal/task"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Server serves the REST API over a task manager. Requests that change
+// tasks are serialized so their several steps and the save that follows
+// are never interleaved with other changes.
+type Server struct {
+	tm  task.ITaskManager
+	mu  sync.RWMutex
+	mux *http.ServeMux
+}
+
+// maxBodySize is the largest request body accepted, far more than any task
+const maxBodySize = 1 << 20
+
+// taskInput is the body of the requests that create a task
+type taskInput struct {
+	Title    string   `json:"title"`
+	Priority string   `json:"priority,omitempty"`
+	Due      string   `json:"due,omitempty"`
+	Reminder string   `json:"reminder,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Estimate string   `json:"estimate,omitempty"`
+}
+
+// taskPatch is the body of the requests that update a task, only the
+// fields present are changed
+type taskPatch struct {
+	Title    *string   `json:"title"`
+	Priority *string   `json:"priority"`
+	Done     *bool     `json:"done"`
+	Tags     *[]string `json:"tags"`
+	Estimate *string   `json:"estimate"`
+}
+
+// timeInput is the body of the requests that set a due date or a reminder
+type timeInput struct {
+	Due      string `json:"due"`
+	Reminder string `json:"reminder"`
+}
+
+// errorResponse is the body of the error responses
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// New creates a server for the task manager
+func New(tm task.ITaskManager) *Server {
+	s := &Server{
+		tm:  tm,
+		mux: http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /tasks", s.listTasks)
+	s.mux.HandleFunc("POST /tasks", s.createTask)
+	s.mux.HandleFunc("GET /tasks/{id}", s.getTask)
+	s.mux.HandleFunc("PATCH /tasks/{id}", s.updateTask)
+	s.mux.HandleFunc("DELETE /tasks/{id}", s.deleteTask)
+	s.mux.HandleFunc("PUT /tasks/{id}/due", s.setDueDate)
+	s.mux.HandleFunc("DELETE /tasks/{id}/due", s.removeDueDate)
+	s.mux.HandleFunc("PUT /tasks/{id}/reminder", s.setReminder)
+	s.mux.HandleFunc("DELETE /tasks/{id}/reminder", s.removeReminder)
+
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// listTasks handles GET /tasks. The query parameters are the list flags:
+// where, due, priority, by-due, urgency, all and archived.
+func (s *Server) listTasks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := task.ListOptions{
+		Due:   query.Get("due"),
+		Where: query.Get("where"),
+	}
+	for name, value := range map[string]*bool{
+		"priority": &opts.ByPriority,
+		"by-due":   &opts.ByDueDate,
+		"urgency":  &opts.ByUrgency,
+		"all":      &opts.All,
+		"archived": &opts.Archived,
+	} {
+		if !query.Has(name) {
+			continue
+		}
+		b, err := strconv.ParseBool(query.Get(name))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid value for %s: %s", name, query.Get(name)))
+			return
+		}
+		*value = b
+	}
+
+	s.mu.RLock()
+	tasks, err := task.ListTasks(s.tm, opts)
+	s.mu.RUnlock()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if tasks == nil {
+		tasks = []task.Task{}
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+// createTask handles POST /tasks
+func (s *Server) createTask(w http.ResponseWriter, r *http.Request) {
+	var in taskInput
+	if !readJSON(w, r, &in) {
+		return
+	}
+
+	// Validate everything before creating the task
+	if in.Title == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("task title is required"))
+		return
+	}
+	priority := task.DefaultPriority
+	if in.Priority != "" {
+		p, err := task.ParsePriority(in.Priority)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid priority: %v", err))
+			return
+		}
+		priority = p
+	}
+	due, err := parseTime(in.Due)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid due date: %v", err))
+		return
+	}
+	reminder, err := parseTime(in.Reminder)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid reminder time: %v", err))
+		return
+	}
+	if err := task.ValidateTimeOrder(due, reminder); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var estimate time.Duration
+	if in.Estimate != "" {
+		estimate, err = parseEstimate(in.Estimate)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid estimate: %v", err))
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	created := s.tm.AddTask(in.Title, priority)
+	id := created.ID
+	ok := s.apply(w,
+		func() error {
+			if len(in.Tags) == 0 {
+				return nil
+			}
+			return s.tm.SetTags(id, in.Tags)
+		},
+		func() error {
+			if estimate == 0 {
+				return nil
+			}
+			return s.tm.SetEstimate(id, estimate)
+		},
+		func() error {
+			if due == nil {
+				return nil
+			}
+			return s.tm.SetDueDate(id, *due)
+		},
+		func() error {
+			if reminder == nil {
+				return nil
+			}
+			return s.tm.SetReminder(id, *reminder)
+		},
+	)
+	if !ok {
+		return
+	}
+
+	created, err = s.tm.GetTaskByID(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/tasks/%d", id))
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// getTask handles GET /tasks/{id}
+func (s *Server) getTask(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.RLock()
+	t, err := s.tm.GetTaskByID(id)
+	s.mu.RUnlock()
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// updateTask handles PATCH /tasks/{id}
+func (s *Server) updateTask(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskID(w, r)
+	if !ok {
+		return
+	}
+	var in taskPatch
+	if !readJSON(w, r, &in) {
+		return
+	}
+
+	// Validate everything before changing the task
+	if in.Title != nil && *in.Title == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("task title can't be empty"))
+		return
+	}
+	var priority *task.TaskPriority
+	if in.Priority != nil {
+		p, err := task.ParsePriority(*in.Priority)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid priority: %v", err))
+			return
+		}
+		priority = &p
+	}
+	var estimate *time.Duration
+	if in.Estimate != nil {
+		var d time.Duration
+		if *in.Estimate != "" {
+			var err error
+			d, err = parseEstimate(*in.Estimate)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid estimate: %v", err))
+				return
+			}
+		}
+		estimate = &d
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.tm.GetTaskByID(id)
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+
+	title := ""
+	if in.Title != nil {
+		title = *in.Title
+	}
+	done := current.Done
+	if in.Done != nil {
+		done = *in.Done
+	}
+
+	ok = s.apply(w,
+		func() error { return s.tm.UpdateTask(id, title, done, priority) },
+		func() error {
+			if in.Tags == nil {
+				return nil
+			}
+			return s.tm.SetTags(id, *in.Tags)
+		},
+		func() error {
+			if estimate == nil {
+				return nil
+			}
+			return s.tm.SetEstimate(id, *estimate)
+		},
+	)
+	if !ok {
+		return
+	}
+
+	s.writeTask(w, id)
+}
+
+// deleteTask handles DELETE /tasks/{id}, the task is moved to the trash
+func (s *Server) deleteTask(w http.ResponseWriter, r *http.Request) {
+	id, ok := taskID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.tm.DeleteTask(id); err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	if err := s.tm.SaveTasks(); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("error saving tasks: %v", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setDueDate handles PUT /tasks/{id}/due
+func (s *Server) setDueDate(w http.ResponseWriter, r *http.Request) {
+	s.setTime(w, r, "due date", func(in timeInput) string { return in.Due }, s.tm.SetDueDate)
+}
+
+// setReminder handles PUT /tasks/{id}/reminder
+func (s *Server) setReminder(w http.ResponseWriter, r *http.Request) {
+	s.setTime(w, r, "reminder time", func(in timeInput) string { return in.Reminder }, s.tm.SetReminder)
+}
+
+// removeDueDate handles DELETE /tasks/{id}/due
+func (s *Server) removeDueDate(w http.ResponseWriter, r *http.Request) {
+	s.removeTime(w, r, s.tm.RemoveDueDate)
+}
+
+// removeReminder handles DELETE /tasks/{id}/reminder
+func (s *Server) removeReminder(w http.ResponseWriter, r *http.Request) {
+	s.removeTime(w, r, s.tm.RemoveReminder)
+}
+
+// setTime sets a date of a task from the request body
+func (s *Server) setTime(w http.ResponseWriter, r *http.Request, name string, value func(timeInput) string, set func(int, time.Time) error) {
+	id, ok := taskID(w, r)
+	if !ok {
+		return
+	}
+	var in timeInput
+	if !readJSON(w, r, &in) {
+		return
+	}
+
+	at, err := parseTime(value(in))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid %s: %v", name, err))
+		return
+	}
+	if at == nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%s is required", name))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := set(id, *at); err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	if err := s.tm.SaveTasks(); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("error saving tasks: %v", err))
+		return
+	}
+	s.writeTask(w, id)
+}
+
+// removeTime removes a date of a task
+func (s *Server) removeTime(w http.ResponseWriter, r *http.Request, remove func(int) error) {
+	id, ok := taskID(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := remove(id); err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	if err := s.tm.SaveTasks(); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("error saving tasks: %v", err))
+		return
+	}
+	s.writeTask(w, id)
+}
+
+// apply runs the changes in order and saves the tasks, stopping at the
+// first error. The input is validated before, so the changes are not
+// expected to fail halfway. It writes the error response and reports
+// whether everything was applied.
+func (s *Server) apply(w http.ResponseWriter, changes ...func() error) bool {
+	for _, change := range changes {
+		if err := change(); err != nil {
+			writeTaskError(w, err)
+			return false
+		}
+	}
+	if err := s.tm.SaveTasks(); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("error saving tasks: %v", err))
+		return false
+	}
+	return true
+}
+
+// writeTask writes the current state of a task
+func (s *Server) writeTask(w http.ResponseWriter, id int) {
+	t, err := s.tm.GetTaskByID(id)
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// taskID parses the task ID of the request path
+func taskID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid task ID: %s", r.PathValue("id")))
+		return 0, false
+	}
+	return id, true
+}
+
+// parseTime parses a date in RFC 3339 or any format accepted by the
+// command line. An empty string is no date.
+func parseTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t, nil
+	}
+	t, err := task.ParseDateTime(s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// parseEstimate parses the estimated effort of a task, which can't be
+// negative
+func parseEstimate(s string) (time.Duration, error) {
+	d, err := task.ParseDuration(s)
+	if err == nil && d < 0 {
+		err = fmt.Errorf("%w: %s is negative", task.ErrInvalidDuration, s)
+	}
+	return d, err
+}
+
+// readJSON decodes the request body, writing the error response on failure
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("request body larger than %d bytes", tooLarge.Limit))
+			return false
+		}
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+// invalidErrors are the errors of invalid input, a bad request
+var invalidErrors = []error{
+	task.ErrTitleRequired, task.ErrInvalidPriority, task.ErrInvalidDate,
+	task.ErrInvalidTimeOrder, task.ErrInvalidDuration, task.ErrInvalidFilter,
+	task.ErrInvalidID, task.ErrInvalidValue,
+}
+
+// writeTaskError writes the error of a task manager operation: unknown
+// tasks are not found, invalid values a bad request, states that don't
+// allow the operation a conflict and anything else an internal error
+func writeTaskError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, task.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, task.ErrAlreadyCompleted), errors.Is(err, task.ErrNotCompleted),
+		errors.Is(err, task.ErrTimerRunning), errors.Is(err, task.ErrNoTimer):
+		status = http.StatusConflict
+	default:
+		for _, invalid := range invalidErrors {
+			if errors.Is(err, invalid) {
+				status = http.StatusBadRequest
+				break
+			}
+		}
+	}
+	writeError(w, status, err)
+}
+
+// writeError writes an error response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
@@ -0,0 +1,72 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors of the task manager and the parsers. The returned errors have
+// more detailed messages, check for these with errors.Is.
+var (
+	// ErrNotFound is returned for the operations on a task that doesn't exist
+	ErrNotFound = errors.New("task not found")
+
+	// Invalid values
+	ErrTitleRequired    = errors.New("task title is required")
+	ErrInvalidPriority  = errors.New("unknown priority")
+	ErrInvalidDate      = errors.New("invalid date time format")
+	ErrInvalidTimeOrder = errors.New("reminder time cannot be after due date")
+	ErrInvalidDuration  = errors.New("invalid duration")
+	ErrInvalidFilter    = errors.New("invalid filter")
+	ErrInvalidID        = errors.New("invalid task ID")
+	ErrInvalidValue     = errors.New("invalid value")
+
+	// States of a task that don't allow an operation
+	ErrAlreadyCompleted = errors.New("task is already completed")
+	ErrNotCompleted     = errors.New("task is not completed")
+	ErrTimerRunning     = errors.New("timer already running")
+	ErrNoTimer          = errors.New("no timer is running")
+)
+
+// NotFoundError is returned when there is no task with the given ID. In is
+// where the task was looked for: the active tasks when empty, "trash" or
+// "archive".
+type NotFoundError struct {
+	ID int
+	In string
+}
+
+// Error implements the error interface
+func (e *NotFoundError) Error() string {
+	if e.In != "" {
+		return fmt.Sprintf("task with ID %d not found in %s", e.ID, e.In)
+	}
+	return fmt.Sprintf("task with ID %d not found", e.ID)
+}
+
+// Is reports whether the target is ErrNotFound
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// detailedError is an error with its own message that matches one of the
+// error variables
+type detailedError struct {
+	msg string
+	err error
+}
+
+// Error implements the error interface
+func (e *detailedError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the error variable it matches
+func (e *detailedError) Unwrap() error {
+	return e.err
+}
+
+// errorf formats an error message for one of the error variables
+func errorf(err error, format string, a ...interface{}) error {
+	return &detailedError{msg: fmt.Sprintf(format, a...), err: err}
+}
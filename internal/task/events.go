@@ -0,0 +1,120 @@
+package task
+
+import (
+	"time"
+)
+
+// Event actions that are not recorded in the task history
+const (
+	// ActionPurged is sent when a task is permanently removed
+	ActionPurged = "purged"
+	// ActionMerged is sent for the tasks added or changed by a merge
+	ActionMerged = "merged"
+)
+
+// Event is a change made to a task. Action is one of the history actions,
+// ActionPurged or ActionMerged, and Task is the task after the change.
+type Event struct {
+	Action string
+	Task   Task
+	At     time.Time
+}
+
+// Subscribe registers a function called after every change made through the
+// task manager. Functions are called once the change is done, from the
+// goroutine that made it, so they can use the task manager. The returned
+// function cancels the subscription.
+func (tm *TaskManager) Subscribe(fn func(Event)) func() {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	if tm.subscribers == nil {
+		tm.subscribers = make(map[int]func(Event))
+	}
+	id := tm.nextSubscriber
+	tm.nextSubscriber++
+	tm.subscribers[id] = fn
+
+	return func() {
+		tm.mu.Lock()
+		defer tm.mu.Unlock()
+		delete(tm.subscribers, id)
+	}
+}
+
+// lock acquires the task manager to change it. The returned function
+// releases it and then sends the events of the change to the subscribers.
+func (tm *TaskManager) lock() func() {
+	tm.mu.Lock()
+	return func() {
+		events := tm.pending
+		tm.pending = nil
+		subscribers := make([]func(Event), 0, len(tm.subscribers))
+		for _, fn := range tm.subscribers {
+			subscribers = append(subscribers, fn)
+		}
+		tm.mu.Unlock()
+
+		for _, e := range events {
+			for _, fn := range subscribers {
+				fn(e)
+			}
+		}
+	}
+}
+
+// emit queues an event for the subscribers, it must be called with the
+// task manager locked
+func (tm *TaskManager) emit(action string, t Task) {
+	if len(tm.subscribers) == 0 {
+		return
+	}
+	t = t.Clone()
+	t.UpdateTimeStatus()
+	tm.pending = append(tm.pending, Event{Action: action, Task: t, At: time.Now()})
+}
+
+// Clone returns a deep copy of the task that shares no memory with it
+func (t Task) Clone() Task {
+	c := t
+	c.Tags = append([]string(nil), t.Tags...)
+	c.DueDate = cloneTime(t.DueDate)
+	c.Reminder = cloneTime(t.Reminder)
+	c.DeletedAt = cloneTime(t.DeletedAt)
+	c.History = append([]HistoryEntry(nil), t.History...)
+	if t.TimeEntries != nil {
+		c.TimeEntries = make([]TimeEntry, len(t.TimeEntries))
+		for i, e := range t.TimeEntries {
+			e.End = cloneTime(e.End)
+			c.TimeEntries[i] = e
+		}
+	}
+	if t.Modified != nil {
+		c.Modified = make(map[string]time.Time, len(t.Modified))
+		for field, at := range t.Modified {
+			c.Modified[field] = at
+		}
+	}
+	return c
+}
+
+// cloneTime copies an optional time
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+// cloneTasks returns deep copies of the tasks
+func cloneTasks(tasks []Task) []Task {
+	if tasks == nil {
+		return nil
+	}
+	clones := make([]Task, len(tasks))
+	for i, t := range tasks {
+		clones[i] = t.Clone()
+	}
+	return clones
+}
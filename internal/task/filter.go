@@ -0,0 +1,288 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Filter selects the tasks that match all of its conditions
+type Filter struct {
+	conditions []func(t Task) bool
+}
+
+// Match reports whether the task matches every condition of the filter
+func (f *Filter) Match(t Task) bool {
+	if f == nil {
+		return true
+	}
+	for _, cond := range f.conditions {
+		if !cond(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the tasks that match the filter
+func (f *Filter) Apply(tasks []Task) []Task {
+	var filtered []Task
+	for _, t := range tasks {
+		if f.Match(t) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// add appends a condition to the filter
+func (f *Filter) add(cond func(t Task) bool) {
+	f.conditions = append(f.conditions, cond)
+}
+
+// ParseFilter parses a filter expression made of space separated terms,
+// all of which must match:
+//
+//	tag:<name>          tasks with the given tag
+//	priority:<level>    tasks with the given priority
+//	status:<status>     done, pending, overdue, duesoon, upcoming or normal
+//	due:<when>          same values accepted by ParseDueFilter
+//	id:<ids>            IDs and ranges, e.g. 3,5,8-12
+//	title:<text>        tasks whose title contains the text
+//	<text>              same as title:<text>
+func ParseFilter(expr string) (*Filter, error) {
+	f := &Filter{}
+
+	for _, term := range strings.Fields(expr) {
+		key, value, found := strings.Cut(term, ":")
+		if !found {
+			key, value = "title", term
+		}
+		if value == "" {
+			return nil, errorf(ErrInvalidFilter, "missing value in filter term: %s", term)
+		}
+
+		switch strings.ToLower(key) {
+		case "tag":
+			tag := normalizeTag(value)
+			f.add(func(t Task) bool { return t.HasTag(tag) })
+		case "priority":
+			priority, err := ParsePriority(value)
+			if err != nil {
+				return nil, err
+			}
+			f.add(func(t Task) bool { return t.Priority == priority })
+		case "status":
+			cond, err := statusCondition(value)
+			if err != nil {
+				return nil, err
+			}
+			f.add(cond)
+		case "due":
+			due, err := ParseDueFilter(value)
+			if err != nil {
+				return nil, err
+			}
+			f.conditions = append(f.conditions, due.conditions...)
+		case "id":
+			ids, err := ParseIDs(value)
+			if err != nil {
+				return nil, err
+			}
+			selected := make(map[int]bool, len(ids))
+			for _, id := range ids {
+				selected[id] = true
+			}
+			f.add(func(t Task) bool { return selected[t.ID] })
+		case "title":
+			text := strings.ToLower(value)
+			f.add(func(t Task) bool { return strings.Contains(strings.ToLower(t.Title), text) })
+		default:
+			return nil, errorf(ErrInvalidFilter, "unknown filter key: %s", key)
+		}
+	}
+
+	return f, nil
+}
+
+// statusCondition returns the condition for a status filter term
+func statusCondition(status string) (func(t Task) bool, error) {
+	timeStatus := map[string]TimeStatus{
+		"normal":   TimeStatusNormal,
+		"upcoming": TimeStatusUpcoming,
+		"duesoon":  TimeStatusDueSoon,
+		"overdue":  TimeStatusOverdue,
+	}
+
+	switch strings.ToLower(status) {
+	case "done":
+		return func(t Task) bool { return t.Done }, nil
+	case "pending":
+		return func(t Task) bool { return !t.Done }, nil
+	}
+
+	ts, ok := timeStatus[strings.ToLower(status)]
+	if !ok {
+		return nil, errorf(ErrInvalidFilter, "unknown status: %s", status)
+	}
+	return func(t Task) bool { return t.GetTimeStatus() == ts }, nil
+}
+
+// DueKeywords are the named periods and statuses accepted by ParseDueFilter
+var DueKeywords = []string{"today", "tomorrow", "thisweek", "nextweek", "overdue", "duesoon", "upcoming"}
+
+// FilterKeys are the keys of the terms of a filter expression
+var FilterKeys = []string{"tag", "priority", "status", "due", "id", "title"}
+
+// ParseDueFilter parses a time filter. Options are today, tomorrow, thisweek,
+// nextweek (tasks due before the end of that period), overdue, duesoon,
+// upcoming, or a specific date, which matches tasks due before it.
+func ParseDueFilter(filter string) (*Filter, error) {
+	f := &Filter{}
+	if filter == "" {
+		return f, nil
+	}
+
+	var dueBefore *time.Time
+	now := time.Now()
+
+	switch filter {
+	case "today":
+		endOfDay := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location())
+		dueBefore = &endOfDay
+	case "tomorrow":
+		tomorrow := now.AddDate(0, 0, 1)
+		endOfTomorrow := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 23, 59, 59, 0, now.Location())
+		dueBefore = &endOfTomorrow
+	case "thisweek":
+		daysUntilEndOfWeek := 7 - int(now.Weekday())
+		if daysUntilEndOfWeek == 0 {
+			daysUntilEndOfWeek = 7
+		}
+		endOfWeek := time.Date(now.Year(), now.Month(), now.Day()+daysUntilEndOfWeek, 23, 59, 59, 0, now.Location())
+		dueBefore = &endOfWeek
+	case "nextweek":
+		startOfNextWeek := now.AddDate(0, 0, 7-int(now.Weekday())+1)
+		endOfNextWeek := startOfNextWeek.AddDate(0, 0, 6)
+		endOfDay := time.Date(endOfNextWeek.Year(), endOfNextWeek.Month(), endOfNextWeek.Day(), 23, 59, 59, 0, now.Location())
+		dueBefore = &endOfDay
+	case "overdue":
+		f.add(func(t Task) bool { return t.GetTimeStatus() == TimeStatusOverdue })
+	case "upcoming":
+		f.add(func(t Task) bool { return t.GetTimeStatus() == TimeStatusUpcoming })
+	case "duesoon":
+		f.add(func(t Task) bool { return t.GetTimeStatus() == TimeStatusDueSoon })
+	default:
+		// Try to parse a specific date
+		date, err := ParseDateTime(filter)
+		if err != nil {
+			return nil, err
+		}
+		dueBefore = &date
+	}
+
+	if dueBefore != nil {
+		limit := *dueBefore
+		f.add(func(t Task) bool { return t.DueDate != nil && !t.DueDate.After(limit) })
+	}
+
+	return f, nil
+}
+
+// MaxIDs is the largest number of task IDs a list of IDs and ranges can
+// select, so a range like 1-2000000000 is refused instead of expanded
+const MaxIDs = 10000
+
+// ParseIDs parses a list of task IDs and ID ranges like "3,5,8-12".
+// The result is sorted and has no duplicates.
+func ParseIDs(s string) ([]int, error) {
+	seen := make(map[int]bool)
+	var ids []int
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		from, to, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, errorf(ErrInvalidID, "invalid task ID: %s", part)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(to); err != nil || end < start {
+				return nil, errorf(ErrInvalidID, "invalid ID range: %s", part)
+			}
+		}
+
+		if end-start >= MaxIDs-len(ids) {
+			return nil, errorf(ErrInvalidID, "too many task IDs: %s (at most %d)", part, MaxIDs)
+		}
+
+		for id := start; id <= end; id++ {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, errorf(ErrInvalidID, "no task IDs given")
+	}
+
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// ListOptions selects and sorts tasks the way the list command does
+type ListOptions struct {
+	ByPriority bool   // Sort by priority
+	ByDueDate  bool   // Sort by due date
+	ByUrgency  bool   // Sort by urgency score, the most urgent first
+	Due        string // Time filter, see ParseDueFilter
+	Where      string // Filter expression, see ParseFilter
+	All        bool   // Include completed tasks
+	Archived   bool   // Archived tasks instead of the active ones
+}
+
+// ListTasks returns the tasks selected by the options
+func ListTasks(tm ITaskManager, opts ListOptions) ([]Task, error) {
+	tf, err := ParseDueFilter(opts.Due)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time filter: %w", err)
+	}
+
+	wf, err := ParseFilter(opts.Where)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	var tasks []Task
+	showCompleted := opts.All
+	if opts.Archived {
+		// Archived tasks are always completed
+		tasks = tm.GetArchivedTasks()
+		showCompleted = true
+	} else {
+		tasks = tm.GetTasksSorted(opts.ByPriority, opts.ByDueDate)
+	}
+
+	var filtered []Task
+	for _, t := range tasks {
+		if t.Done && !showCompleted {
+			continue
+		}
+		if tf.Match(t) && wf.Match(t) {
+			filtered = append(filtered, t)
+		}
+	}
+	if opts.ByUrgency {
+		SortByUrgency(filtered, time.Now())
+	}
+	return filtered, nil
+}
@@ -0,0 +1,52 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParseIDs(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []int
+		wantErr bool
+	}{
+		{"3", []int{3}, false},
+		{"3,5,8-10", []int{3, 5, 8, 9, 10}, false},
+		{" 5 , 3 ,, 3-4 ", []int{3, 4, 5}, false},
+		{"7-7", []int{7}, false},
+		{"", nil, true},
+		{",", nil, true},
+		{"a", nil, true},
+		{"-1", nil, true},
+		{"5-3", nil, true},
+		{"1-x", nil, true},
+		{fmt.Sprintf("1-%d", MaxIDs), []int(nil), false},
+		{fmt.Sprintf("1-%d", MaxIDs+1), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseIDs(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidID) {
+					t.Fatalf("ParseIDs(%q) error = %v, want %v", tt.in, err, ErrInvalidID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseIDs(%q): %v", tt.in, err)
+			}
+			if tt.want == nil {
+				// Only the count is checked for the long ranges
+				if len(got) != MaxIDs {
+					t.Errorf("ParseIDs(%q) returned %d IDs, want %d", tt.in, len(got), MaxIDs)
+				}
+				return
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ParseIDs(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
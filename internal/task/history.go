@@ -0,0 +1,79 @@
+package task
+
+import (
+	"os"
+	"time"
+)
+
+// HistoryEntry is an event in the life of a task. Field changes record
+// the field name with its old and new values.
+type HistoryEntry struct {
+	Action   string    `json:"action"`
+	Field    string    `json:"field,omitempty"`
+	OldValue string    `json:"old_value,omitempty"`
+	NewValue string    `json:"new_value,omitempty"`
+	Note     string    `json:"note,omitempty"`
+	User     string    `json:"user,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+// History actions
+const (
+	ActionCreated    = "created"
+	ActionImported   = "imported"
+	ActionUpdated    = "updated"
+	ActionCompleted  = "completed"
+	ActionReopened   = "reopened"
+	ActionDeleted    = "deleted"
+	ActionRestored   = "restored"
+	ActionArchived   = "archived"
+	ActionUnarchived = "unarchived"
+
+	ActionTimerStarted = "timer started"
+	ActionTimerStopped = "timer stopped"
+	ActionTimeLogged   = "time logged"
+)
+
+// addHistory records an event in the task history
+func (t *Task) addHistory(action, note string) {
+	t.History = append(t.History, HistoryEntry{
+		Action: action,
+		Note:   note,
+		User:   currentUser(),
+		At:     time.Now(),
+	})
+}
+
+// recordChange records the change of a field in the task history and its
+// modification time, nothing is recorded if the value didn't change
+func (t *Task) recordChange(field, oldValue, newValue string) {
+	if oldValue == newValue {
+		return
+	}
+	now := time.Now()
+	t.History = append(t.History, HistoryEntry{
+		Action:   ActionUpdated,
+		Field:    field,
+		OldValue: oldValue,
+		NewValue: newValue,
+		User:     currentUser(),
+		At:       now,
+	})
+	t.setModified(field, now)
+}
+
+// historyTime formats an optional time for the history
+func historyTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// currentUser returns the name of the user running the program
+func currentUser() string {
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+	return os.Getenv("USERNAME")
+}
@@ -7,9 +7,24 @@ import "time"
 type ITaskManager interface {
 	// Operaciones básicas
 	AddTask(title string, priority TaskPriority) Task
+	ImportTask(t Task) (Task, error)
 	GetTaskByID(id int) (Task, error)
 	DeleteTask(id int) error
+
+	// Papelera
+	RestoreTask(id int) error
+	GetTrashedTasks() []Task
+	EmptyTrash(olderThan time.Duration) int
+
+	// Archivo de tareas completadas
+	ArchiveTasks(ids []int, olderThan time.Duration) ([]Task, error)
+	UnarchiveTask(id int) error
+	GetArchivedTasks() []Task
 	UpdateTask(id int, title string, done bool, priority *TaskPriority) error
+	UpdateTasks(ids []int, changes TaskChanges) ([]Task, error)
+	SetTags(id int, tags []string) error
+	CompleteTask(id int, note string) error
+	ReopenTask(id int, note string) error
 
 	// Manejo de fechas y recordatorios
 	SetDueDate(id int, date time.Time) error
@@ -17,10 +32,23 @@ type ITaskManager interface {
 	RemoveDueDate(id int) error
 	RemoveReminder(id int) error
 
+	// Seguimiento de tiempo
+	StartTimer(id int) error
+	StopTimer() (Task, error)
+	ActiveTimer() (Task, bool)
+	LogTime(id int, d time.Duration, note string) error
+	SetEstimate(id int, estimate time.Duration) error
+
 	// Consultas y listados
 	GetTasksSorted(byPriority, byDueDate bool) []Task
 	GetTasksByTimeStatus(status TimeStatus) []Task
 
+	// Sincronización
+	Merge(base, other []Task) MergeResult
+
+	// Notificaciones de cambios
+	Subscribe(fn func(Event)) func()
+
 	// Persistencia
 	LoadTasks() error
 	SaveTasks() error
@@ -0,0 +1,309 @@
+package task
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MergeConflict describes a task changed on both sides of a merge and how
+// it was resolved
+type MergeConflict struct {
+	ID         int    `json:"id"`
+	Title      string `json:"title"`
+	Field      string `json:"field,omitempty"`
+	Resolution string `json:"resolution"`
+}
+
+// MergeResult summarizes a merge
+type MergeResult struct {
+	Added     int             `json:"added"`
+	Updated   int             `json:"updated"`
+	Removed   int             `json:"removed"`
+	Conflicts []MergeConflict `json:"conflicts,omitempty"`
+}
+
+// mergeField is a group of task fields merged as a unit. The name is the
+// one used in the history and the modification times.
+type mergeField struct {
+	name string
+	get  func(t Task) interface{}
+	set  func(dst *Task, src Task)
+}
+
+// mergeFields are the fields merged one by one, time entries and the history
+// are combined instead
+var mergeFields = []mergeField{
+	{"title", func(t Task) interface{} { return t.Title }, func(d *Task, s Task) { d.Title = s.Title }},
+	{"priority", func(t Task) interface{} { return t.Priority }, func(d *Task, s Task) { d.Priority = s.Priority }},
+	{"tags", func(t Task) interface{} { return t.Tags }, func(d *Task, s Task) { d.Tags = s.Tags }},
+	{"due_date", func(t Task) interface{} { return t.DueDate }, func(d *Task, s Task) { d.DueDate = s.DueDate }},
+	{"reminder", func(t Task) interface{} { return t.Reminder }, func(d *Task, s Task) { d.Reminder = s.Reminder }},
+	{"estimate", func(t Task) interface{} { return t.Estimate }, func(d *Task, s Task) { d.Estimate = s.Estimate }},
+	{"done", func(t Task) interface{} { return []interface{}{t.Done, t.CompletedAt, t.CompletionNote} }, func(d *Task, s Task) {
+		d.Done, d.CompletedAt, d.CompletionNote = s.Done, s.CompletedAt, s.CompletionNote
+	}},
+	{"deleted_at", func(t Task) interface{} { return t.DeletedAt }, func(d *Task, s Task) { d.DeletedAt = s.DeletedAt }},
+}
+
+// MergeTasks merges two lists of tasks, matching them by UID. When base, the
+// common ancestor of both lists, is given the merge is three-way: a field
+// changed on a single side keeps that change. Fields changed on both sides,
+// or any differing field when base is nil, take the most recently modified
+// value. Time entries and history are combined. Tasks added on the remote
+// side with an ID already in use locally are renumbered.
+func MergeTasks(base, local, remote []Task) ([]Task, MergeResult) {
+	var result MergeResult
+
+	baseByKey := make(map[string]Task)
+	for _, t := range base {
+		baseByKey[mergeKey(t)] = t
+	}
+	remoteByKey := make(map[string]Task)
+	var remoteKeys []string
+	for _, t := range remote {
+		key := mergeKey(t)
+		remoteByKey[key] = t
+		remoteKeys = append(remoteKeys, key)
+	}
+
+	usedIDs := make(map[int]bool)
+	nextID := 1
+	for _, list := range [][]Task{base, local, remote} {
+		for _, t := range list {
+			if t.ID >= nextID {
+				nextID = t.ID + 1
+			}
+		}
+	}
+
+	var merged []Task
+	seen := make(map[string]bool)
+	for _, l := range local {
+		key := mergeKey(l)
+		seen[key] = true
+		b, inBase := baseByKey[key]
+		r, inRemote := remoteByKey[key]
+
+		switch {
+		case inRemote:
+			var hasBase *Task
+			if inBase {
+				hasBase = &b
+			}
+			t, conflicts := mergeTask(hasBase, l, r)
+			if !sameTask(t, l) {
+				result.Updated++
+			}
+			result.Conflicts = append(result.Conflicts, conflicts...)
+			merged = append(merged, t)
+		case inBase && sameTask(b, l):
+			// Removed on the remote side
+			result.Removed++
+			continue
+		case inBase:
+			merged = append(merged, l)
+			result.Conflicts = append(result.Conflicts, MergeConflict{ID: l.ID, Title: l.Title, Resolution: "removed remotely but changed locally, kept"})
+		default:
+			merged = append(merged, l)
+		}
+		usedIDs[l.ID] = true
+	}
+
+	for _, key := range remoteKeys {
+		if seen[key] {
+			continue
+		}
+		r := remoteByKey[key]
+		b, inBase := baseByKey[key]
+
+		if inBase {
+			if sameTask(b, r) {
+				// Removed on the local side
+				continue
+			}
+			result.Conflicts = append(result.Conflicts, MergeConflict{ID: r.ID, Title: r.Title, Resolution: "removed locally but changed remotely, kept"})
+		}
+
+		if usedIDs[r.ID] {
+			result.Conflicts = append(result.Conflicts, MergeConflict{
+				ID:         r.ID,
+				Title:      r.Title,
+				Resolution: fmt.Sprintf("ID already in use, renumbered to %d", nextID),
+			})
+			r.ID = nextID
+			nextID++
+		}
+		usedIDs[r.ID] = true
+		r.UpdateTimeStatus()
+		merged = append(merged, r)
+		result.Added++
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].ID < merged[j].ID
+	})
+	return merged, result
+}
+
+// mergeTask merges two versions of the same task field by field. The local
+// ID is kept.
+func mergeTask(base *Task, local, remote Task) (Task, []MergeConflict) {
+	merged := local
+	merged.Modified = make(map[string]time.Time, len(local.Modified))
+	for field, at := range local.Modified {
+		merged.Modified[field] = at
+	}
+	var conflicts []MergeConflict
+
+	for _, f := range mergeFields {
+		lv, rv := f.get(local), f.get(remote)
+		if sameValue(lv, rv) {
+			continue
+		}
+
+		if base != nil {
+			bv := f.get(*base)
+			if sameValue(bv, lv) {
+				f.set(&merged, remote)
+				merged.setModified(f.name, remote.Modified[f.name])
+				continue
+			}
+			if sameValue(bv, rv) {
+				continue
+			}
+		}
+
+		// Both sides changed the field, or there is no base to tell which did
+		winner := "local"
+		if remote.Modified[f.name].After(local.Modified[f.name]) {
+			winner = "remote"
+			f.set(&merged, remote)
+			merged.setModified(f.name, remote.Modified[f.name])
+		}
+
+		// Without base it is only a conflict when both sides edited the field
+		edited := !local.Modified[f.name].IsZero() && !remote.Modified[f.name].IsZero()
+		if base != nil || edited {
+			conflicts = append(conflicts, MergeConflict{
+				ID:         local.ID,
+				Title:      merged.Title,
+				Field:      f.name,
+				Resolution: fmt.Sprintf("changed on both sides, kept %s value", winner),
+			})
+		}
+	}
+
+	merged.TimeEntries = mergeTimeEntries(local.TimeEntries, remote.TimeEntries)
+	merged.History = mergeHistory(local.History, remote.History)
+	merged.UpdateTimeStatus()
+	return merged, conflicts
+}
+
+// mergeTimeEntries combines the time entries of both sides, entries with the
+// same start are the same entry and a finished one wins over a running one
+func mergeTimeEntries(local, remote []TimeEntry) []TimeEntry {
+	entries := append([]TimeEntry(nil), local...)
+	for _, r := range remote {
+		found := false
+		for i, e := range entries {
+			if e.Start.Equal(r.Start) {
+				if e.End == nil && r.End != nil {
+					entries[i] = r
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			entries = append(entries, r)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start.Before(entries[j].Start)
+	})
+	return entries
+}
+
+// mergeHistory combines the history of both sides in chronological order
+func mergeHistory(local, remote []HistoryEntry) []HistoryEntry {
+	history := append([]HistoryEntry(nil), local...)
+	for _, r := range remote {
+		found := false
+		for _, h := range local {
+			if sameValue(h, r) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			history = append(history, r)
+		}
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].At.Before(history[j].At)
+	})
+	return history
+}
+
+// mergeKey identifies a task across copies of the data
+func mergeKey(t Task) string {
+	if t.UID != "" {
+		return t.UID
+	}
+	return legacyUID(t)
+}
+
+// sameTask reports whether two tasks have the same stored content
+func sameTask(a, b Task) bool {
+	return sameValue(a, b)
+}
+
+// sameValue reports whether two values are stored the same way
+func sameValue(a, b interface{}) bool {
+	aData, errA := json.Marshal(a)
+	bData, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(aData, bData)
+}
+
+// setModified records when a field of the task was last changed
+func (t *Task) setModified(field string, at time.Time) {
+	if at.IsZero() {
+		return
+	}
+	if t.Modified == nil {
+		t.Modified = make(map[string]time.Time)
+	}
+	t.Modified[field] = at
+}
+
+// legacyUID returns the UID of a task saved before tasks had one. It is
+// derived from the ID and creation time (a version 5 UUID) so every copy of
+// the data gives the same UID to the same task.
+func legacyUID(t Task) string {
+	h := sha1.Sum([]byte(fmt.Sprintf("task-cli:%d:%d", t.ID, t.CreatedAt.UnixNano())))
+	b := h[:16]
+	b[6] = b[6]&0x0f | 0x50
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b)
+}
+
+// newUID returns a random (version 4) UUID
+func newUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b[:])
+}
+
+// formatUUID formats 16 bytes as an UUID
+func formatUUID(b []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,171 @@
+package task
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergeTasks(t *testing.T) {
+	created := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	earlier, later := created.Add(time.Hour), created.Add(2*time.Hour)
+	base := Task{ID: 1, UID: "a", Title: "base", Priority: PriorityLow, CreatedAt: created}
+	with := func(change func(t *Task)) Task {
+		t := base
+		change(&t)
+		return t
+	}
+
+	tests := []struct {
+		name      string
+		base      []Task
+		local     []Task
+		remote    []Task
+		want      []Task
+		result    MergeResult
+		conflicts []string // fields or resolutions of the conflicts
+	}{
+		{
+			name:   "unchanged",
+			base:   []Task{base},
+			local:  []Task{base},
+			remote: []Task{base},
+			want:   []Task{base},
+		},
+		{
+			name:   "changed remotely",
+			base:   []Task{base},
+			local:  []Task{base},
+			remote: []Task{with(func(t *Task) { t.Title = "remote"; t.setModified("title", later) })},
+			want:   []Task{with(func(t *Task) { t.Title = "remote"; t.setModified("title", later) })},
+			result: MergeResult{Updated: 1},
+		},
+		{
+			name:   "different fields changed on each side",
+			base:   []Task{base},
+			local:  []Task{with(func(t *Task) { t.Priority = PriorityHigh; t.setModified("priority", earlier) })},
+			remote: []Task{with(func(t *Task) { t.Title = "remote"; t.setModified("title", later) })},
+			want: []Task{with(func(t *Task) {
+				t.Title, t.Priority = "remote", PriorityHigh
+				t.setModified("priority", earlier)
+				t.setModified("title", later)
+			})},
+			result: MergeResult{Updated: 1},
+		},
+		{
+			name:      "same field changed on both sides",
+			base:      []Task{base},
+			local:     []Task{with(func(t *Task) { t.Title = "local"; t.setModified("title", earlier) })},
+			remote:    []Task{with(func(t *Task) { t.Title = "remote"; t.setModified("title", later) })},
+			want:      []Task{with(func(t *Task) { t.Title = "remote"; t.setModified("title", later) })},
+			result:    MergeResult{Updated: 1},
+			conflicts: []string{"title"},
+		},
+		{
+			name:      "newer local value kept",
+			base:      []Task{base},
+			local:     []Task{with(func(t *Task) { t.Title = "local"; t.setModified("title", later) })},
+			remote:    []Task{with(func(t *Task) { t.Title = "remote"; t.setModified("title", earlier) })},
+			want:      []Task{with(func(t *Task) { t.Title = "local"; t.setModified("title", later) })},
+			conflicts: []string{"title"},
+		},
+		{
+			name:   "no base, the newer value wins without a conflict",
+			local:  []Task{base},
+			remote: []Task{with(func(t *Task) { t.Title = "remote"; t.setModified("title", later) })},
+			want:   []Task{with(func(t *Task) { t.Title = "remote"; t.setModified("title", later) })},
+			result: MergeResult{Updated: 1},
+		},
+		{
+			name:   "removed remotely",
+			base:   []Task{base},
+			local:  []Task{base},
+			remote: []Task{},
+			want:   nil,
+			result: MergeResult{Removed: 1},
+		},
+		{
+			name:      "removed remotely but changed locally",
+			base:      []Task{base},
+			local:     []Task{with(func(t *Task) { t.Title = "local" })},
+			remote:    []Task{},
+			want:      []Task{with(func(t *Task) { t.Title = "local" })},
+			conflicts: []string{"removed remotely"},
+		},
+		{
+			name:   "removed locally",
+			base:   []Task{base},
+			local:  []Task{},
+			remote: []Task{base},
+			want:   nil,
+		},
+		{
+			name:   "added remotely",
+			local:  []Task{},
+			remote: []Task{base},
+			want:   []Task{base},
+			result: MergeResult{Added: 1},
+		},
+		{
+			name:      "added on both sides with the same ID",
+			local:     []Task{base},
+			remote:    []Task{with(func(t *Task) { t.UID = "b"; t.Title = "remote" })},
+			want:      []Task{base, with(func(t *Task) { t.ID = 2; t.UID = "b"; t.Title = "remote" })},
+			result:    MergeResult{Added: 1},
+			conflicts: []string{"renumbered to 2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, result := MergeTasks(tt.base, tt.local, tt.remote)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d tasks, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if !sameTask(got[i], tt.want[i]) {
+					t.Errorf("task %d: got %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+
+			if result.Added != tt.result.Added || result.Updated != tt.result.Updated || result.Removed != tt.result.Removed {
+				t.Errorf("got result %+v, want %+v", result, tt.result)
+			}
+			if len(result.Conflicts) != len(tt.conflicts) {
+				t.Fatalf("got conflicts %+v, want %v", result.Conflicts, tt.conflicts)
+			}
+			for i, c := range result.Conflicts {
+				if c.Field != tt.conflicts[i] && !strings.Contains(c.Resolution, tt.conflicts[i]) {
+					t.Errorf("got conflict %+v, want %q", c, tt.conflicts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMergeTimeEntries(t *testing.T) {
+	start := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	running := TimeEntry{Start: start}
+	finished := TimeEntry{Start: start, End: &end}
+	other := TimeEntry{Start: start.Add(-time.Hour), End: &start}
+
+	tests := []struct {
+		name   string
+		local  []TimeEntry
+		remote []TimeEntry
+		want   []TimeEntry
+	}{
+		{"same entry", []TimeEntry{finished}, []TimeEntry{finished}, []TimeEntry{finished}},
+		{"finished remotely", []TimeEntry{running}, []TimeEntry{finished}, []TimeEntry{finished}},
+		{"finished locally", []TimeEntry{finished}, []TimeEntry{running}, []TimeEntry{finished}},
+		{"combined in order", []TimeEntry{finished}, []TimeEntry{other}, []TimeEntry{other, finished}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeTimeEntries(tt.local, tt.remote); !sameValue(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
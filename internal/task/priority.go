@@ -1,59 +1,212 @@
 package task
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // TaskPriority represents the priority of a task
 type TaskPriority int
 
-// Task priorities constants default is Medium
+// Task priorities constants default is Medium. Low, Medium and High keep
+// the values they always had in the task files, the other levels are around
+// them. PriorityNone is an explicit "no priority", lower than all the rest.
 const (
-	PriorityLow TaskPriority = iota
+	PriorityNone TaskPriority = iota - 2
+	PrioritySomeday
+	PriorityLow
 	PriorityMedium
 	PriorityHigh
+	PriorityUrgent
 
 	DefaultPriority = PriorityMedium
 )
 
+// Priorities lists the priorities from the lowest to the highest
+var Priorities = []TaskPriority{PriorityNone, PrioritySomeday, PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent}
+
+// priorityKeys are the fixed names of the priorities, always accepted by
+// ParsePriority and used in the configuration
+var priorityKeys = map[TaskPriority]string{
+	PriorityNone:    "none",
+	PrioritySomeday: "someday",
+	PriorityLow:     "low",
+	PriorityMedium:  "medium",
+	PriorityHigh:    "high",
+	PriorityUrgent:  "urgent",
+}
+
+// PriorityStyle is how a priority is shown
+type PriorityStyle struct {
+	Name  string
+	Color string // Name of the color, see Colors
+}
+
+// priorityStyles are the names and colors of the priorities, the
+// configuration can replace them with SetPriorityStyle
+var priorityStyles = map[TaskPriority]PriorityStyle{
+	PriorityNone:    {"None", "default"},
+	PrioritySomeday: {"Someday", "gray"},
+	PriorityLow:     {"Low", "green"},
+	PriorityMedium:  {"Medium", "yellow"},
+	PriorityHigh:    {"High", "red"},
+	PriorityUrgent:  {"Urgent", "magenta"},
+}
+
+// Colors are the names of the colors accepted for the priorities with
+// their terminal codes
+var Colors = map[string]string{
+	"default": "\033[0m",
+	"red":     "\033[0;31m",
+	"green":   "\033[0;32m",
+	"yellow":  "\033[0;33m",
+	"blue":    "\033[0;34m",
+	"magenta": "\033[0;35m",
+	"cyan":    "\033[0;36m",
+	"white":   "\033[0;37m",
+	"gray":    "\033[0;90m",
+	"bold":    "\033[1m",
+}
+
 // String returns the string representation of a TaskPriority
 func (p TaskPriority) String() string {
-	switch p {
-	case PriorityLow:
-		return "Low"
-	case PriorityMedium:
-		return "Medium"
-	case PriorityHigh:
-		return "High"
-	default:
-		return "Unknown"
+	if style, ok := priorityStyles[p]; ok {
+		return style.Name
 	}
+	return "Unknown"
 }
 
-// ParsePriority parses a string and returns the corresponding TaskPriority
+// Key returns the fixed name of a TaskPriority, like "high", whatever
+// name it's shown with
+func (p TaskPriority) Key() string {
+	return priorityKeys[p]
+}
+
+// ParsePriority parses a string and returns the corresponding TaskPriority.
+// Both the fixed names (none, someday, low, medium, high, urgent) and the
+// names set in the configuration are accepted, in any case.
 func ParsePriority(s string) (TaskPriority, error) {
-	switch s {
-	case "low", "Low", "LOW":
-		return PriorityLow, nil
-	case "medium", "Medium", "MEDIUM":
-		return PriorityMedium, nil
-	case "high", "High", "HIGH":
-		return PriorityHigh, nil
-	default:
-		return DefaultPriority, fmt.Errorf("unknown priority: %s", s)
+	name := strings.ToLower(strings.TrimSpace(s))
+	for _, p := range Priorities {
+		if name == priorityKeys[p] || name == strings.ToLower(priorityStyles[p].Name) {
+			return p, nil
+		}
 	}
+	return DefaultPriority, fmt.Errorf("%w: %s", ErrInvalidPriority, s)
 }
 
 // Color returns the color code for a TaskPriority
 func (p TaskPriority) Color() string {
-	switch p {
-	case PriorityLow:
-		return "\033[0;32m" // Green
-	case PriorityMedium:
-		return "\033[0;33m" // Yellow
-	case PriorityHigh:
-		return "\033[0;31m" // Red
-	default:
-		return "\033[0m" // Reset
+	if code, ok := Colors[priorityStyles[p].Color]; ok {
+		return code
+	}
+	return "\033[0m" // Reset
+}
+
+// SetPriorityStyle changes the name or the color a priority is shown with,
+// empty values keep the current ones
+func SetPriorityStyle(p TaskPriority, style PriorityStyle) error {
+	current, ok := priorityStyles[p]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrInvalidPriority, p)
+	}
+	if style.Color != "" {
+		if _, ok := Colors[style.Color]; !ok {
+			return errorf(ErrInvalidValue, "unknown color: %s", style.Color)
+		}
+		current.Color = style.Color
+	}
+	if style.Name != "" {
+		// The name can't be the one of another priority
+		if other, err := ParsePriority(style.Name); err == nil && other != p {
+			return errorf(ErrInvalidValue, "priority name %s is taken by %s", style.Name, other.Key())
+		}
+		current.Name = style.Name
+	}
+	priorityStyles[p] = current
+	return nil
+}
+
+// PriorityAging raises the priority of the pending tasks that keep the
+// same one for too long
+type PriorityAging struct {
+	// After is the time without a priority change before raising it a level
+	After time.Duration
+	// Max is the highest priority reached by aging
+	Max TaskPriority
+}
+
+// AgePriorities raises one level the priority of the pending tasks that
+// haven't had their priority changed for aging.After, up to aging.Max.
+// Every raise is a change of the priority, so the next one comes after
+// aging.After again. Tasks without priority or for someday are left alone.
+// It returns the tasks that were raised.
+func AgePriorities(tm *TaskManager, aging PriorityAging, now time.Time) []Task {
+	if aging.After <= 0 {
+		return nil
+	}
+
+	var raised []Task
+	for _, t := range tm.GetTasksSorted(false, false) {
+		if t.Done || t.Priority < PriorityLow || t.Priority >= aging.Max {
+			continue
+		}
+
+		since := t.CreatedAt
+		if changed, ok := t.Modified["priority"]; ok && changed.After(since) {
+			since = changed
+		}
+		if now.Sub(since) < aging.After {
+			continue
+		}
+
+		// The task may have changed since it was listed, by another client
+		// of the daemon or the server
+		if t, ok := tm.RaisePriority(t.ID, t.Priority); ok {
+			raised = append(raised, t)
+		}
+	}
+	return raised
+}
+
+// priorityAging is how the priorities age, off until the configuration
+// sets it with SetPriorityAging
+var priorityAging PriorityAging
+
+// SetPriorityAging sets how the priorities age, see WatchAging
+func SetPriorityAging(aging PriorityAging) {
+	priorityAging = aging
+}
+
+// WatchAging ages the priorities right away and then every interval until
+// the context is done, saving the tasks when any was raised. The errors are
+// passed to report. Only long running commands like daemon and serve age
+// the priorities, a one-off command never changes the tasks on its own.
+func WatchAging(ctx context.Context, tm *TaskManager, interval time.Duration, report func(error)) {
+	aging := priorityAging
+	if aging.After <= 0 {
+		return
+	}
+
+	age := func() {
+		if raised := AgePriorities(tm, aging, time.Now()); len(raised) > 0 {
+			if err := tm.SaveTasks(); err != nil {
+				report(err)
+			}
+		}
+	}
+
+	age()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			age()
+		}
 	}
 }
@@ -0,0 +1,84 @@
+package task
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAgePriorities(t *testing.T) {
+	now := time.Now()
+	aging := PriorityAging{After: 24 * time.Hour, Max: PriorityHigh}
+
+	tests := []struct {
+		name     string
+		priority TaskPriority
+		age      time.Duration
+		done     bool
+		want     TaskPriority
+	}{
+		{"old enough", PriorityLow, 48 * time.Hour, false, PriorityMedium},
+		{"too recent", PriorityLow, time.Hour, false, PriorityLow},
+		{"at the maximum", PriorityHigh, 48 * time.Hour, false, PriorityHigh},
+		{"completed", PriorityLow, 48 * time.Hour, true, PriorityLow},
+		{"someday", PrioritySomeday, 48 * time.Hour, false, PrioritySomeday},
+		{"no priority", PriorityNone, 48 * time.Hour, false, PriorityNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := newTestManager(t, 0)
+			if _, err := tm.ImportTask(Task{Title: tt.name, Priority: tt.priority, Done: tt.done, CreatedAt: now.Add(-tt.age)}); err != nil {
+				t.Fatal(err)
+			}
+
+			AgePriorities(tm, aging, now)
+			got, err := tm.GetTaskByID(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Priority != tt.want || got.Done != tt.done {
+				t.Errorf("got priority %v done %v, want %v done %v", got.Priority, got.Done, tt.want, tt.done)
+			}
+		})
+	}
+}
+
+func TestRaisePriorityAfterChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(tm *TaskManager) error
+		raised bool
+		want   TaskPriority
+		done   bool
+	}{
+		{"unchanged", func(tm *TaskManager) error { return nil }, true, PriorityMedium, false},
+		{"completed", func(tm *TaskManager) error { return tm.CompleteTask(1, "") }, false, PriorityLow, true},
+		{"priority changed", func(tm *TaskManager) error {
+			high := PriorityHigh
+			return tm.UpdateTask(1, "", false, &high)
+		}, false, PriorityHigh, false},
+		{"deleted", func(tm *TaskManager) error { return tm.DeleteTask(1) }, false, PriorityLow, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := newTestManager(t, 0)
+			listed := tm.AddTask(tt.name, PriorityLow)
+			if err := tt.change(tm); err != nil {
+				t.Fatal(err)
+			}
+
+			// The task as listed before the change
+			_, raised := tm.RaisePriority(listed.ID, listed.Priority)
+			if raised != tt.raised {
+				t.Errorf("raised = %v, want %v", raised, tt.raised)
+			}
+
+			tasks := append(tm.GetTasksSorted(false, false), tm.GetTrashedTasks()...)
+			if len(tasks) != 1 {
+				t.Fatalf("got %d tasks, want 1", len(tasks))
+			}
+			if tasks[0].Priority != tt.want || tasks[0].Done != tt.done {
+				t.Errorf("got priority %v done %v, want %v done %v", tasks[0].Priority, tasks[0].Done, tt.want, tt.done)
+			}
+		})
+	}
+}
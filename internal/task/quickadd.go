@@ -0,0 +1,236 @@
+package task
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QuickAdd is what ParseQuickAdd understood of a quick-add text
+type QuickAdd struct {
+	Title string
+	// Priority is only meaningful when HasPriority is set
+	Priority    TaskPriority
+	HasPriority bool
+	Tags        []string
+	DueDate     *time.Time
+	Reminder    *time.Time
+}
+
+// clockPattern matches the times of day accepted after a date: 17:00, 9:30,
+// 5pm or 5:30pm
+var clockPattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+
+// weekdays are the names of the days accepted as dates
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ParseQuickAdd parses a task written in one line, like
+// "Fix login bug !high due:fri 17:00 #backend remind:-2h". The words it
+// understands are:
+//
+//	!<priority>            the priority, like !high
+//	#tag                   a tag, can be repeated
+//	due:<date> [time]      the due date
+//	remind:<date> [time]   the reminder, or remind:-2h to be reminded before
+//	                       the due date
+//
+// Dates are the ones of ParseDateTime, today, tomorrow, a day of the week
+// (its next occurrence, today included) or +3d for a time from now, counted
+// from the date and time of day of now in its location. They are wall-clock
+// times like the ones of ParseDateTime. Without a time of day the date is at
+// the end of the day. The rest of the words are the title, like the !words
+// that aren't a priority, and a word starting with a backslash is kept as
+// is, without it.
+func ParseQuickAdd(text string, now time.Time) (QuickAdd, error) {
+	var q QuickAdd
+	var title []string
+	var remind string
+
+	// The dates are computed from the wall clock of now, so they come out
+	// as wall-clock times
+	now = WallClock(now)
+
+	words := strings.Fields(text)
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		lower := strings.ToLower(word)
+
+		switch {
+		case strings.HasPrefix(word, `\`) && len(word) > 1:
+			title = append(title, word[1:])
+		case strings.HasPrefix(word, "!") && len(word) > 1:
+			// Words like "Wow!great" or "!important" are left in the title
+			p, err := ParsePriority(lower[1:])
+			if err != nil {
+				title = append(title, word)
+				break
+			}
+			q.Priority, q.HasPriority = p, true
+		case strings.HasPrefix(word, "#") && len(word) > 1:
+			q.Tags = append(q.Tags, word)
+		case strings.HasPrefix(lower, "due:"):
+			value, used := withClock(word[len("due:"):], words[i+1:])
+			i += used
+			due, err := parseQuickDate(value, now)
+			if err != nil {
+				return q, err
+			}
+			q.DueDate = &due
+		case strings.HasPrefix(lower, "remind:"):
+			value, used := withClock(word[len("remind:"):], words[i+1:])
+			i += used
+			remind = value
+		default:
+			title = append(title, word)
+		}
+	}
+
+	q.Title = strings.Join(title, " ")
+	if q.Title == "" {
+		return q, ErrTitleRequired
+	}
+	q.Tags = NormalizeTags(q.Tags)
+
+	// The reminder can be relative to the due date, wherever that is
+	if remind != "" {
+		reminder, err := parseQuickReminder(remind, q.DueDate, now)
+		if err != nil {
+			return q, err
+		}
+		q.Reminder = &reminder
+	}
+	if err := ValidateTimeOrder(q.DueDate, q.Reminder); err != nil {
+		return q, err
+	}
+	return q, nil
+}
+
+// withClock joins a date with the time of day written after it, returning
+// how many of the following words it took
+func withClock(date string, next []string) (string, int) {
+	if len(next) == 0 {
+		return date, 0
+	}
+	// A bare number like "3" is left in the title, "25:00" is a wrong time
+	word := strings.ToLower(next[0])
+	if clockPattern.MatchString(word) && strings.ContainsAny(word, ":apm") {
+		return date + " " + next[0], 1
+	}
+	return date, 0
+}
+
+// parseQuickReminder parses the value of remind:, either a date or a
+// duration before the due date like -2h or -1d
+func parseQuickReminder(value string, due *time.Time, now time.Time) (time.Time, error) {
+	if before, ok := strings.CutPrefix(value, "-"); ok {
+		d, err := ParseDuration(before)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if due == nil {
+			return time.Time{}, errorf(ErrInvalidDate, "reminder %s needs a due date", value)
+		}
+		return due.Add(-d), nil
+	}
+	return parseQuickDate(value, now)
+}
+
+// parseQuickDate parses a date of the quick-add syntax, with an optional
+// time of day after a space
+func parseQuickDate(value string, now time.Time) (time.Time, error) {
+	date, clock, hasClock := strings.Cut(value, " ")
+	lower := strings.ToLower(date)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var day time.Time
+	switch {
+	case lower == "today":
+		day = today
+	case lower == "tomorrow":
+		day = today.AddDate(0, 0, 1)
+	case strings.HasPrefix(lower, "+"):
+		d, err := ParseDuration(lower[1:])
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !hasClock {
+			// +2h is a moment, +3d a day
+			if d < 24*time.Hour {
+				return now.Add(d).Truncate(time.Minute), nil
+			}
+		}
+		// Whole days on the calendar, 24 hours can be another time across DST
+		day = today.AddDate(0, 0, int(d/(24*time.Hour)))
+	default:
+		if wd, ok := weekdays[lower]; ok {
+			days := (int(wd) - int(now.Weekday()) + 7) % 7
+			day = today.AddDate(0, 0, days)
+			break
+		}
+		t, err := ParseDateTime(date)
+		if err != nil {
+			return time.Time{}, err
+		}
+		// Dates like 2024-01-10T15:00 already have their time of day
+		if t.Hour() != 0 || t.Minute() != 0 {
+			return t, nil
+		}
+		day = t
+	}
+
+	if !hasClock {
+		return endOfDay(day), nil
+	}
+	hour, minute, err := parseClock(clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location()), nil
+}
+
+// parseClock parses a time of day, returning its hour and minute
+func parseClock(s string) (hour, minute int, err error) {
+	invalid := errorf(ErrInvalidDate, "invalid time of day: %s", s)
+	m := clockPattern.FindStringSubmatch(strings.ToLower(s))
+	if m == nil {
+		return 0, 0, invalid
+	}
+	hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+
+	switch m[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, invalid
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+	default:
+		// A bare hour like "17" is not a time, it could be part of the title
+		if m[2] == "" {
+			return 0, 0, invalid
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, invalid
+	}
+	return hour, minute, nil
+}
+
+// endOfDay returns the last minute of the day of t
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 0, 0, t.Location())
+}
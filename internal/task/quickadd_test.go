@@ -0,0 +1,93 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	// Thursday 01:30 in UTC+2, still Wednesday in UTC
+	now := time.Date(2030, 1, 10, 1, 30, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+
+	tests := []struct {
+		text     string
+		title    string
+		priority string // Key of the priority, empty when not given
+		tags     string
+		due      string // As given to -due, empty for none
+		reminder string
+		wantErr  error
+	}{
+		{text: "Fix bug", title: "Fix bug"},
+		{text: "Fix bug !high #Backend #api", title: "Fix bug", priority: "high", tags: "[backend api]"},
+		{text: "Wow !important stuff", title: "Wow !important stuff"},
+		{text: `\#1 \!high issue`, title: "#1 !high issue"},
+		{text: "a due:today", title: "a", due: "2030-01-10 23:59"},
+		{text: "a due:thu", title: "a", due: "2030-01-10 23:59"},
+		{text: "a due:fri 17:00", title: "a", due: "2030-01-11 17:00"},
+		{text: "a due:tomorrow 9am", title: "a", due: "2030-01-11 09:00"},
+		{text: "a due:2030-02-01 5:30pm", title: "a", due: "2030-02-01 17:30"},
+		{text: "a due:2030-02-01T08:00", title: "a", due: "2030-02-01 08:00"},
+		{text: "a due:+3d", title: "a", due: "2030-01-13 23:59"},
+		{text: "a due:+2h", title: "a", due: "2030-01-10 03:30"},
+		{text: "a due:fri 3", title: "a 3", due: "2030-01-11 23:59"},
+		{text: "a due:fri 17:00 remind:-2h", title: "a", due: "2030-01-11 17:00", reminder: "2030-01-11 15:00"},
+		{text: "a remind:tomorrow 8:00", title: "a", reminder: "2030-01-11 08:00"},
+		{text: "!high", wantErr: ErrTitleRequired},
+		{text: "a remind:-2h", wantErr: ErrInvalidDate},
+		{text: "a due:someday", wantErr: ErrInvalidDate},
+		{text: "a due:tomorrow 13pm", wantErr: ErrInvalidDate},
+		{text: "a due:fri remind:sat", wantErr: ErrInvalidTimeOrder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			q, err := ParseQuickAdd(tt.text, now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if q.Title != tt.title {
+				t.Errorf("got title %q, want %q", q.Title, tt.title)
+			}
+			priority := ""
+			if q.HasPriority {
+				priority = q.Priority.Key()
+			}
+			if priority != tt.priority {
+				t.Errorf("got priority %q, want %q", priority, tt.priority)
+			}
+			if tags := fmt.Sprint(q.Tags); tt.tags != "" && tags != tt.tags || tt.tags == "" && len(q.Tags) > 0 {
+				t.Errorf("got tags %v, want %s", q.Tags, tt.tags)
+			}
+			checkQuickDate(t, "due date", q.DueDate, tt.due)
+			checkQuickDate(t, "reminder", q.Reminder, tt.reminder)
+		})
+	}
+}
+
+// checkQuickDate checks that a quick-add date is the one -due would give
+// for want
+func checkQuickDate(t *testing.T, name string, got *time.Time, want string) {
+	t.Helper()
+	if want == "" {
+		if got != nil {
+			t.Errorf("got %s %v, want none", name, got)
+		}
+		return
+	}
+	w, err := ParseDateTime(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || !got.Equal(w) || got.Location() != w.Location() {
+		t.Errorf("got %s %v, want %v", name, got, w)
+	}
+}
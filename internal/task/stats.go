@@ -0,0 +1,168 @@
+package task
+
+import (
+	"time"
+)
+
+// Stats summarizes the state of a list of tasks
+type Stats struct {
+	Total            int            `json:"total"`
+	Open             int            `json:"open"`
+	Completed        int            `json:"completed"`
+	OpenByPriority   map[string]int `json:"open_by_priority"`
+	OpenByTimeStatus map[string]int `json:"open_by_time_status"`
+
+	// Completion of the tasks that had a due date
+	CompletedWithDue int     `json:"completed_with_due"`
+	CompletedOnTime  int     `json:"completed_on_time"`
+	CompletedLate    int     `json:"completed_late"`
+	OnTimeRate       float64 `json:"on_time_rate"`
+
+	// Average time from creation to completion
+	AverageLeadTime      time.Duration `json:"-"`
+	AverageLeadTimeHours float64       `json:"average_lead_time_hours"`
+}
+
+// ComputeStats calculates the statistics of the given tasks
+func ComputeStats(tasks []Task) Stats {
+	s := Stats{
+		OpenByPriority:   make(map[string]int),
+		OpenByTimeStatus: make(map[string]int),
+	}
+
+	var totalLeadTime time.Duration
+	for _, t := range tasks {
+		s.Total++
+
+		if !t.Done {
+			s.Open++
+			s.OpenByPriority[t.Priority.String()]++
+			s.OpenByTimeStatus[t.GetTimeStatus().String()]++
+			continue
+		}
+
+		s.Completed++
+		totalLeadTime += t.CompletedAt.Sub(t.CreatedAt)
+
+		if t.DueDate != nil {
+			s.CompletedWithDue++
+			if t.CompletedAt.After(*t.DueDate) {
+				s.CompletedLate++
+			} else {
+				s.CompletedOnTime++
+			}
+		}
+	}
+
+	if s.CompletedWithDue > 0 {
+		s.OnTimeRate = float64(s.CompletedOnTime) / float64(s.CompletedWithDue)
+	}
+	if s.Completed > 0 {
+		s.AverageLeadTime = totalLeadTime / time.Duration(s.Completed)
+		s.AverageLeadTimeHours = s.AverageLeadTime.Hours()
+	}
+
+	return s
+}
+
+// Report intervals
+const (
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+// ReportPeriod holds the activity of a day or week
+type ReportPeriod struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Created   int       `json:"created"`
+	Completed int       `json:"completed"`
+}
+
+// BurndownPoint is the number of open tasks at the end of a day
+type BurndownPoint struct {
+	Date time.Time `json:"date"`
+	Open int       `json:"open"`
+}
+
+// Report holds the activity of a list of tasks over a date range
+type Report struct {
+	From     time.Time       `json:"from"`
+	To       time.Time       `json:"to"`
+	Interval string          `json:"interval"`
+	Periods  []ReportPeriod  `json:"periods"`
+	Burndown []BurndownPoint `json:"burndown"`
+}
+
+// MaxReportDays is the longest range a report can cover
+const MaxReportDays = 3660
+
+// BuildReport calculates the tasks created and completed per day or week,
+// and the daily burndown of open tasks, between the days of from and to.
+// Only the dates of from and to count, the days are local days whatever
+// their location, so a date parsed as UTC and time.Now() give the same day.
+func BuildReport(tasks []Task, from, to time.Time, interval string) (Report, error) {
+	from = startOfDay(from)
+	to = startOfDay(to)
+	if to.Before(from) {
+		return Report{}, errorf(ErrInvalidValue, "report end date is before its start date")
+	}
+	if to.Sub(from) > MaxReportDays*24*time.Hour {
+		return Report{}, errorf(ErrInvalidValue, "report range is longer than %d days", MaxReportDays)
+	}
+
+	r := Report{From: from, To: to, Interval: interval}
+
+	switch interval {
+	case IntervalDay:
+		for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+			r.Periods = append(r.Periods, ReportPeriod{Start: day, End: day.AddDate(0, 0, 1)})
+		}
+	case IntervalWeek:
+		// Weeks start on Monday
+		offset := (int(from.Weekday()) + 6) % 7
+		for week := from.AddDate(0, 0, -offset); !week.After(to); week = week.AddDate(0, 0, 7) {
+			r.Periods = append(r.Periods, ReportPeriod{Start: week, End: week.AddDate(0, 0, 7)})
+		}
+	default:
+		return Report{}, errorf(ErrInvalidValue, "unknown report interval: %s", interval)
+	}
+
+	for i := range r.Periods {
+		p := &r.Periods[i]
+		for _, t := range tasks {
+			if inPeriod(t.CreatedAt, p.Start, p.End) {
+				p.Created++
+			}
+			if t.Done && inPeriod(t.CompletedAt, p.Start, p.End) {
+				p.Completed++
+			}
+		}
+	}
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		endOfDay := day.AddDate(0, 0, 1)
+		open := 0
+		for _, t := range tasks {
+			if !t.CreatedAt.Before(endOfDay) {
+				continue
+			}
+			if !t.Done || !t.CompletedAt.Before(endOfDay) {
+				open++
+			}
+		}
+		r.Burndown = append(r.Burndown, BurndownPoint{Date: day, Open: open})
+	}
+
+	return r, nil
+}
+
+// inPeriod shows if t is within [start, end)
+func inPeriod(t, start, end time.Time) bool {
+	return !t.Before(start) && t.Before(end)
+}
+
+// startOfDay returns the local midnight of the date of t
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
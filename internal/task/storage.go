@@ -4,62 +4,244 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"time"
 )
 
-const fileName = "tasks.json"
+const (
+	fileName        = "tasks.json"
+	archiveFileName = "archive.json"
+)
+
+// TaskFiles are the data files that hold lists of tasks
+var TaskFiles = []string{fileName, archiveFileName}
 
 func (tm *TaskManager) SaveTasks() error {
+	// Saving takes the write lock so two saves never write the files at once
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
 
-	data, err := json.MarshalIndent(tm.tasks, "", "  ")
-	if err != nil {
-		return err
+	if tm.inMemory {
+		return nil
 	}
 
-	homeDir, err := os.UserHomeDir()
+	dataDir, err := tm.dataDir()
 	if err != nil {
 		return err
 	}
 
-	dataDir := filepath.Join(homeDir, ".task-cli")
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return err
 	}
 
-	filePath := filepath.Join(dataDir, fileName)
-	err = os.WriteFile(filePath, data, 0644)
-	if err != nil {
-		return err
+	// The file that gains tasks is written first, so a failure between the
+	// two writes leaves a task in both files rather than in none. LoadTasks
+	// keeps the newest copy.
+	writeTasks := func() error {
+		return writeTasksFile(filepath.Join(dataDir, fileName), tm.tasks)
+	}
+	writeArchive := func() error {
+		// The archive is only written once something has been archived
+		archivePath := filepath.Join(dataDir, archiveFileName)
+		if _, err := os.Stat(archivePath); len(tm.archived) > 0 || err == nil {
+			return writeTasksFile(archivePath, tm.archived)
+		}
+		return nil
 	}
 
+	writes := []func() error{writeArchive, writeTasks}
+	if tm.unarchived {
+		writes = []func() error{writeTasks, writeArchive}
+	}
+	for _, write := range writes {
+		if err := write(); err != nil {
+			return err
+		}
+	}
+	tm.unarchived = false
+
 	return nil
 }
 
 func (tm *TaskManager) LoadTasks() error {
-	homeDir, err := os.UserHomeDir()
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	dataDir, err := tm.dataDir()
 	if err != nil {
 		return err
 	}
 
-	filePath := filepath.Join(homeDir, ".task-cli", fileName)
-	data, err := os.ReadFile(filePath)
-	if os.IsNotExist(err) {
-		return nil
-	}
+	// Start from scratch so loading again doesn't keep tasks or fields
+	// removed from the files
+	tm.tasks, tm.archived = nil, nil
 
-	if err != nil {
+	if err := readTasksFile(filepath.Join(dataDir, fileName), &tm.tasks); err != nil {
 		return err
 	}
 
-	if err := json.Unmarshal(data, &tm.tasks); err != nil {
+	if err := readTasksFile(filepath.Join(dataDir, archiveFileName), &tm.archived); err != nil {
 		return err
 	}
 
-	// Update nextID base on the highest ID find in the tasks
+	// Update nextID base on the highest ID find in the tasks, archived
+	// tasks included so their IDs are never reused
 	for _, task := range tm.tasks {
 		if task.ID >= tm.nextID {
 			tm.nextID = task.ID + 1
 		}
 	}
+	for _, task := range tm.archived {
+		if task.ID >= tm.nextID {
+			tm.nextID = task.ID + 1
+		}
+	}
 
+	tm.dropStaleCopies()
+	tm.ensureIdentity()
 	return nil
 }
+
+// dropStaleCopies removes the older copy of the tasks found both in the
+// active list and in the archive, left by a save that failed halfway
+func (tm *TaskManager) dropStaleCopies() {
+	archived := make(map[string]int)
+	for i, t := range tm.archived {
+		if t.UID != "" {
+			archived[t.UID] = i
+		}
+	}
+
+	staleArchived := make(map[int]bool)
+	active := tm.tasks[:0]
+	for _, t := range tm.tasks {
+		i, ok := archived[t.UID]
+		if !ok || t.UID == "" {
+			active = append(active, t)
+			continue
+		}
+		if lastChange(t).After(lastChange(tm.archived[i])) {
+			staleArchived[i] = true
+			active = append(active, t)
+		}
+	}
+	tm.tasks = active
+
+	if len(staleArchived) > 0 {
+		kept := tm.archived[:0]
+		for i, t := range tm.archived {
+			if !staleArchived[i] {
+				kept = append(kept, t)
+			}
+		}
+		tm.archived = kept
+	}
+}
+
+// lastChange returns the time of the last event in the history of a task
+func lastChange(t Task) time.Time {
+	if len(t.History) == 0 {
+		return t.CreatedAt
+	}
+	return t.History[len(t.History)-1].At
+}
+
+// ensureIdentity gives an UID to the tasks saved without one and a new ID to
+// the tasks that share it with another one, which can happen after merging
+// data from several machines. Archived tasks keep their IDs.
+func (tm *TaskManager) ensureIdentity() {
+	used := make(map[int]bool)
+	for _, list := range [][]Task{tm.archived, tm.tasks} {
+		for i := range list {
+			if list[i].UID == "" {
+				list[i].UID = legacyUID(list[i])
+			}
+			if used[list[i].ID] {
+				list[i].ID = tm.nextID
+				tm.nextID++
+			}
+			used[list[i].ID] = true
+		}
+	}
+}
+
+// dataDir returns the directory where the task manager stores its files
+func (tm *TaskManager) dataDir() (string, error) {
+	if tm.dir != "" {
+		return tm.dir, nil
+	}
+	return DataDir()
+}
+
+// dataDirOverride replaces the default data directory when set
+var dataDirOverride string
+
+// SetDataDir replaces the default data directory, for the --db flag
+func SetDataDir(dir string) {
+	dataDirOverride = dir
+}
+
+// DataDir returns the default directory where the task files are stored
+func DataDir() (string, error) {
+	if dataDirOverride != "" {
+		return dataDirOverride, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".task-cli"), nil
+}
+
+// writeTasksFile writes a list of tasks as JSON
+func writeTasksFile(filePath string, tasks []Task) error {
+	if tasks == nil {
+		tasks = []Task{}
+	}
+
+	data, err := json.MarshalIndent(tasks, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filePath, data)
+}
+
+// writeFileAtomic replaces a file with new content through a temporary
+// file, so the file has either its old or its new content, never a part
+func writeFileAtomic(filePath string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+// readTasksFile reads a list of tasks from a JSON file, a missing file is not an error
+func readTasksFile(filePath string, tasks *[]Task) error {
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, tasks)
+}
@@ -3,19 +3,29 @@ package task
 import (
 	"fmt"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
 type Task struct {
-	ID          int          `json:"id"`
-	Title       string       `json:"title"`
-	Done        bool         `json:"done"`
-	Priority    TaskPriority `json:"priority"`
-	CreatedAt   time.Time    `json:"created_at"`
-	CompletedAt time.Time    `json:"completed_at"`
-	DueDate     *time.Time   `json:"due_date,omitempty"`
-	Reminder    *time.Time   `json:"reminder,omitempty"`
-	timeStatus  TimeStatus   `json:"-"` // Is calculated but it won't be shown in the JSON
+	ID             int                  `json:"id"`
+	UID            string               `json:"uid,omitempty"`
+	Title          string               `json:"title"`
+	Done           bool                 `json:"done"`
+	Priority       TaskPriority         `json:"priority"`
+	Tags           []string             `json:"tags,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+	CompletedAt    time.Time            `json:"completed_at"`
+	CompletionNote string               `json:"completion_note,omitempty"`
+	DueDate        *time.Time           `json:"due_date,omitempty"`
+	Reminder       *time.Time           `json:"reminder,omitempty"`
+	DeletedAt      *time.Time           `json:"deleted_at,omitempty"`
+	Estimate       time.Duration        `json:"estimate,omitempty"`
+	TimeEntries    []TimeEntry          `json:"time_entries,omitempty"`
+	History        []HistoryEntry       `json:"history,omitempty"`
+	Modified       map[string]time.Time `json:"modified,omitempty"`
+	timeStatus     TimeStatus           `json:"-"` // Is calculated but it won't be shown in the JSON
 }
 
 // GetTimeStatus returns the time status of a task based on its due date and reminder
@@ -46,98 +56,323 @@ func (t *Task) IsOverdue() bool {
 	return t.timeStatus == TimeStatusOverdue
 }
 
+// setDone changes the completion status of the task recording it in the history
+func (t *Task) setDone(done bool, note string) {
+	t.Done = done
+	if done {
+		t.stopTimer()
+		t.CompletedAt = time.Now()
+		t.CompletionNote = note
+		t.addHistory(ActionCompleted, note)
+	} else {
+		t.CompletedAt = time.Time{}
+		t.CompletionNote = ""
+		t.addHistory(ActionReopened, note)
+	}
+	t.setModified("done", time.Now())
+}
+
+// HasTag shows if the task has the given tag
+func (t *Task) HasTag(tag string) bool {
+	tag = normalizeTag(tag)
+	for _, tt := range t.Tags {
+		if tt == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDeleted shows if the task has been moved to the trash
+func (t *Task) IsDeleted() bool {
+	return t.DeletedAt != nil
+}
+
+// TaskManager holds the tasks. It is safe for concurrent use, the tasks it
+// returns are copies that can be used freely.
 type TaskManager struct {
-	tasks  []Task
-	nextID int
+	mu       sync.RWMutex
+	dir      string
+	tasks    []Task
+	archived []Task
+	nextID   int
+	// unarchived is set when tasks moved back from the archive since the
+	// last save, so tasks.json is written first
+	unarchived bool
+	// inMemory managers never write their files, see Snapshot
+	inMemory bool
+
+	subscribers    map[int]func(Event)
+	nextSubscriber int
+	pending        []Event
 }
 
 func NewTaskManager() *TaskManager {
 	return &TaskManager{
-		tasks:  make([]Task, 0),
-		nextID: 1,
+		tasks:    make([]Task, 0),
+		archived: make([]Task, 0),
+		nextID:   1,
 	}
 }
 
-// AddTask creates a new task and adds it to the task manager
-func (tm *TaskManager) AddTask(title string, priority TaskPriority) Task {
-	if priority == 0 {
-		priority = DefaultPriority
+// NewTaskManagerAt creates a task manager that stores its tasks in dir
+// instead of the default data directory
+func NewTaskManagerAt(dir string) *TaskManager {
+	tm := NewTaskManager()
+	tm.dir = dir
+	return tm
+}
+
+// Snapshot returns a task manager holding copies of the active, trashed
+// and archived tasks of tm, to work out what a change would do without
+// making it: the subscribers of tm aren't notified and SaveTasks does
+// nothing.
+func Snapshot(tm ITaskManager) *TaskManager {
+	s := NewTaskManager()
+	s.inMemory = true
+	s.tasks = append(tm.GetTasksSorted(false, false), tm.GetTrashedTasks()...)
+	s.archived = tm.GetArchivedTasks()
+	for _, list := range [][]Task{s.tasks, s.archived} {
+		for _, t := range list {
+			if t.ID >= s.nextID {
+				s.nextID = t.ID + 1
+			}
+		}
 	}
+	return s
+}
+
+// AddTask creates a new task and adds it to the task manager with the
+// given priority, callers without one pass DefaultPriority
+func (tm *TaskManager) AddTask(title string, priority TaskPriority) Task {
+	unlock := tm.lock()
+	defer unlock()
 
 	task := Task{
 		ID:        tm.nextID,
+		UID:       newUID(),
 		Title:     title,
 		Done:      false,
 		Priority:  priority,
 		CreatedAt: time.Now(),
 	}
 
+	task.addHistory(ActionCreated, "")
 	task.UpdateTimeStatus()
 	tm.tasks = append(tm.tasks, task)
 	tm.nextID++
-	return task
+	tm.emit(ActionCreated, task)
+	return task.Clone()
 }
 
-// SetDueDate stablish a due date for a task
-func (tm *TaskManager) SetDueDate(id int, dueDate time.Time) error {
-	for i, task := range tm.tasks {
-		if task.ID == id {
-			// Validar que el recordatorio (si existe) sea anterior a la fecha límite
-			if err := ValidateTimeOrder(&dueDate, task.Reminder); err != nil {
-				return err
+// ImportTask adds a task created by another application. The task gets a
+// new ID, the rest of its fields are kept. Its UID can't be the one of an
+// active, trashed or archived task, merges tell the tasks apart by UID.
+func (tm *TaskManager) ImportTask(t Task) (Task, error) {
+	if t.Title == "" {
+		return Task{}, ErrTitleRequired
+	}
+	if err := ValidateTimeOrder(t.DueDate, t.Reminder); err != nil {
+		return Task{}, err
+	}
+
+	unlock := tm.lock()
+	defer unlock()
+
+	if t.UID != "" {
+		for _, list := range [][]Task{tm.tasks, tm.archived} {
+			for _, existing := range list {
+				if existing.UID == t.UID {
+					return Task{}, errorf(ErrInvalidValue, "UID %s is already used by task %d", t.UID, existing.ID)
+				}
 			}
-			tm.tasks[i].DueDate = &dueDate
-			tm.tasks[i].UpdateTimeStatus()
-			return nil
 		}
 	}
-	return fmt.Errorf("task with ID %d not found", id)
+
+	t = t.Clone()
+	t.ID = tm.nextID
+	if t.UID == "" {
+		t.UID = newUID()
+	}
+	t.Tags = NormalizeTags(t.Tags)
+	t.DeletedAt = nil
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	if t.Done && t.CompletedAt.IsZero() {
+		t.CompletedAt = time.Now()
+	}
+	if !t.Done {
+		t.CompletedAt = time.Time{}
+	}
+
+	t.addHistory(ActionImported, "")
+	t.UpdateTimeStatus()
+	tm.tasks = append(tm.tasks, t)
+	tm.nextID++
+	tm.emit(ActionImported, t)
+	return t.Clone(), nil
 }
 
-// SetReminder stablish a reminder for a task
-func (tm *TaskManager) SetReminder(id int, reminder time.Time) error {
-	for i, task := range tm.tasks {
-		if task.ID == id {
-			// Validate that the reminder (if exists) is before the due date
-			if err := ValidateTimeOrder(task.DueDate, &reminder); err != nil {
-				return err
+// Merge merges tasks saved by another copy of the program into the active
+// tasks, see MergeTasks. Base is the common version both copies started
+// from, nil when unknown. Tasks that are archived locally stay archived.
+func (tm *TaskManager) Merge(base, other []Task) MergeResult {
+	unlock := tm.lock()
+	defer unlock()
+
+	archived := make(map[string]Task)
+	for _, t := range tm.archived {
+		archived[t.UID] = t
+	}
+
+	var incoming []Task
+	var conflicts []MergeConflict
+	for _, t := range other {
+		if a, ok := archived[t.UID]; ok && t.UID != "" {
+			if !sameTask(a, t) {
+				conflicts = append(conflicts, MergeConflict{ID: a.ID, Title: a.Title, Resolution: "archived locally, kept archived"})
 			}
-			tm.tasks[i].Reminder = &reminder
-			tm.tasks[i].UpdateTimeStatus()
-			return nil
+			continue
+		}
+		incoming = append(incoming, t)
+	}
+	var common []Task
+	if base != nil {
+		common = []Task{}
+		for _, t := range base {
+			if _, ok := archived[t.UID]; !ok || t.UID == "" {
+				common = append(common, t)
+			}
+		}
+	}
+
+	before := make(map[string]Task, len(tm.tasks))
+	for _, t := range tm.tasks {
+		before[t.UID] = t
+	}
+
+	merged, result := MergeTasks(common, tm.tasks, cloneTasks(incoming))
+	result.Conflicts = append(conflicts, result.Conflicts...)
+	tm.tasks = merged
+
+	// Incoming tasks may use IDs of archived tasks
+	for _, t := range tm.tasks {
+		if t.ID >= tm.nextID {
+			tm.nextID = t.ID + 1
+		}
+	}
+	for _, t := range tm.archived {
+		for i := range tm.tasks {
+			if tm.tasks[i].ID == t.ID {
+				result.Conflicts = append(result.Conflicts, MergeConflict{
+					ID:         t.ID,
+					Title:      tm.tasks[i].Title,
+					Resolution: fmt.Sprintf("ID already in use, renumbered to %d", tm.nextID),
+				})
+				tm.tasks[i].ID = tm.nextID
+				tm.nextID++
+			}
+		}
+	}
+
+	for _, t := range tm.tasks {
+		if old, ok := before[t.UID]; !ok || !sameTask(old, t) {
+			tm.emit(ActionMerged, t)
 		}
+		delete(before, t.UID)
 	}
-	return fmt.Errorf("task with ID %d not found", id)
+	for _, t := range before {
+		tm.emit(ActionPurged, t)
+	}
+
+	return result
+}
+
+// SetDueDate stablish a due date for a task
+func (tm *TaskManager) SetDueDate(id int, dueDate time.Time) error {
+	unlock := tm.lock()
+	defer unlock()
+
+	i := tm.indexOf(id)
+	if i < 0 {
+		return &NotFoundError{ID: id}
+	}
+	// Validar que el recordatorio (si existe) sea anterior a la fecha límite
+	if err := ValidateTimeOrder(&dueDate, tm.tasks[i].Reminder); err != nil {
+		return err
+	}
+	tm.tasks[i].recordChange("due_date", historyTime(tm.tasks[i].DueDate), historyTime(&dueDate))
+	tm.tasks[i].DueDate = &dueDate
+	tm.tasks[i].UpdateTimeStatus()
+	tm.emit(ActionUpdated, tm.tasks[i])
+	return nil
+}
+
+// SetReminder stablish a reminder for a task
+func (tm *TaskManager) SetReminder(id int, reminder time.Time) error {
+	unlock := tm.lock()
+	defer unlock()
+
+	i := tm.indexOf(id)
+	if i < 0 {
+		return &NotFoundError{ID: id}
+	}
+	// Validate that the reminder (if exists) is before the due date
+	if err := ValidateTimeOrder(tm.tasks[i].DueDate, &reminder); err != nil {
+		return err
+	}
+	tm.tasks[i].recordChange("reminder", historyTime(tm.tasks[i].Reminder), historyTime(&reminder))
+	tm.tasks[i].Reminder = &reminder
+	tm.tasks[i].UpdateTimeStatus()
+	tm.emit(ActionUpdated, tm.tasks[i])
+	return nil
 }
 
 // RemoveDueDate remove the due date of a task
 func (tm *TaskManager) RemoveDueDate(id int) error {
-	for i, task := range tm.tasks {
-		if task.ID == id {
-			tm.tasks[i].DueDate = nil
-			tm.tasks[i].UpdateTimeStatus()
-			return nil
-		}
+	unlock := tm.lock()
+	defer unlock()
+
+	i := tm.indexOf(id)
+	if i < 0 {
+		return &NotFoundError{ID: id}
 	}
-	return fmt.Errorf("task with ID %d not found", id)
+	tm.tasks[i].recordChange("due_date", historyTime(tm.tasks[i].DueDate), "")
+	tm.tasks[i].DueDate = nil
+	tm.tasks[i].UpdateTimeStatus()
+	tm.emit(ActionUpdated, tm.tasks[i])
+	return nil
 }
 
 // RemoveReminder remove the reminder of a task
 func (tm *TaskManager) RemoveReminder(id int) error {
-	for i, task := range tm.tasks {
-		if task.ID == id {
-			tm.tasks[i].Reminder = nil
-			tm.tasks[i].UpdateTimeStatus()
-			return nil
-		}
+	unlock := tm.lock()
+	defer unlock()
+
+	i := tm.indexOf(id)
+	if i < 0 {
+		return &NotFoundError{ID: id}
 	}
-	return fmt.Errorf("task with ID %d not found", id)
+	tm.tasks[i].recordChange("reminder", historyTime(tm.tasks[i].Reminder), "")
+	tm.tasks[i].Reminder = nil
+	tm.tasks[i].UpdateTimeStatus()
+	tm.emit(ActionUpdated, tm.tasks[i])
+	return nil
 }
 
-// GetTasksSorted returns the tasks sorted by priority and due date
+// GetTasksSorted returns the tasks sorted by priority and due date.
+// Tasks in the trash are not included.
 func (tm *TaskManager) GetTasksSorted(byPriority bool, byDueDate bool) []Task {
-	sorted := make([]Task, len(tm.tasks))
-	copy(sorted, tm.tasks)
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	sorted := make([]Task, 0, len(tm.tasks))
+	for _, task := range tm.tasks {
+		if !task.IsDeleted() {
+			sorted = append(sorted, task.Clone())
+		}
+	}
 
 	// Update time status for each task
 	for i := range sorted {
@@ -171,62 +406,424 @@ func (tm *TaskManager) GetTasksSorted(byPriority bool, byDueDate bool) []Task {
 
 // GetTasksByTimeStatus returns the tasks filtered by time status
 func (tm *TaskManager) GetTasksByTimeStatus(status TimeStatus) []Task {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
 	var filtered []Task
 	for _, task := range tm.tasks {
+		if task.IsDeleted() {
+			continue
+		}
 		task.UpdateTimeStatus()
 		if task.timeStatus == status {
-			filtered = append(filtered, task)
+			filtered = append(filtered, task.Clone())
 		}
 	}
 	return filtered
 }
 
+// SetTags replaces the tags of a task
+func (tm *TaskManager) SetTags(id int, tags []string) error {
+	unlock := tm.lock()
+	defer unlock()
+
+	i := tm.indexOf(id)
+	if i < 0 {
+		return &NotFoundError{ID: id}
+	}
+	newTags := NormalizeTags(tags)
+	tm.tasks[i].recordChange("tags", strings.Join(tm.tasks[i].Tags, ","), strings.Join(newTags, ","))
+	tm.tasks[i].Tags = newTags
+	tm.emit(ActionUpdated, tm.tasks[i])
+	return nil
+}
+
+// NormalizeTags lowercases the tags, removing the leading '#', empty
+// values and duplicates
+func NormalizeTags(tags []string) []string {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// normalizeTag returns the canonical form of a tag
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
 // UpdateTask update a task with new values
 func (tm *TaskManager) UpdateTask(id int, title string, done bool, priority *TaskPriority) error {
-	for i, task := range tm.tasks {
-		if task.ID == id {
-			if title != "" {
-				tm.tasks[i].Title = title
-			}
-			if priority != nil {
-				tm.tasks[i].Priority = *priority
-			}
+	unlock := tm.lock()
+	defer unlock()
 
-			// Update done status
-			if done != task.Done {
-				tm.tasks[i].Done = done
-				if done {
-					tm.tasks[i].CompletedAt = time.Now()
-				} else {
-					tm.tasks[i].CompletedAt = time.Time{}
-				}
-			}
+	i := tm.indexOf(id)
+	if i < 0 {
+		return &NotFoundError{ID: id}
+	}
 
-			tm.tasks[i].UpdateTimeStatus()
-			return nil
+	if title != "" {
+		tm.tasks[i].recordChange("title", tm.tasks[i].Title, title)
+		tm.tasks[i].Title = title
+	}
+	if priority != nil {
+		tm.tasks[i].recordChange("priority", tm.tasks[i].Priority.String(), priority.String())
+		tm.tasks[i].Priority = *priority
+	}
+
+	// Update done status. Completing or reopening is the event sent, like
+	// with CompleteTask and ReopenTask, so its hooks run.
+	action := ActionUpdated
+	if done != tm.tasks[i].Done {
+		tm.tasks[i].setDone(done, "")
+		action = ActionReopened
+		if done {
+			action = ActionCompleted
 		}
 	}
-	return fmt.Errorf("task with ID %d not found", id)
+
+	tm.tasks[i].UpdateTimeStatus()
+	tm.emit(action, tm.tasks[i])
+	return nil
+}
+
+// TaskChanges are the changes UpdateTasks makes to every task. Empty values
+// leave the fields as they are.
+type TaskChanges struct {
+	Title          string         `json:"title,omitempty"`
+	Complete       bool           `json:"complete,omitempty"`
+	Priority       *TaskPriority  `json:"priority,omitempty"`
+	SetTags        bool           `json:"set_tags,omitempty"` // Tags replace the tags, even when empty
+	Tags           []string       `json:"tags,omitempty"`
+	Estimate       *time.Duration `json:"estimate,omitempty"`
+	DueDate        *time.Time     `json:"due_date,omitempty"`
+	RemoveDueDate  bool           `json:"remove_due_date,omitempty"`
+	Reminder       *time.Time     `json:"reminder,omitempty"`
+	RemoveReminder bool           `json:"remove_reminder,omitempty"`
+}
+
+// times returns the due date and reminder of the task once changed
+func (c TaskChanges) times(t Task) (due, reminder *time.Time) {
+	due, reminder = t.DueDate, t.Reminder
+	if c.RemoveDueDate {
+		due = nil
+	} else if c.DueDate != nil {
+		due = c.DueDate
+	}
+	if c.RemoveReminder {
+		reminder = nil
+	} else if c.Reminder != nil {
+		reminder = c.Reminder
+	}
+	return due, reminder
+}
+
+// Validate checks that the changes can be made to the task
+func (c TaskChanges) Validate(t Task) error {
+	return ValidateTimeOrder(c.times(t))
+}
+
+// UpdateTasks makes the same changes to several tasks at once. Every task is
+// checked before any is changed, so either all of them change or none does.
+// Each task sends a single event, ActionCompleted when it gets completed.
+// It returns the changed tasks.
+func (tm *TaskManager) UpdateTasks(ids []int, changes TaskChanges) ([]Task, error) {
+	if changes.Estimate != nil && *changes.Estimate < 0 {
+		return nil, errorf(ErrInvalidValue, "estimate can't be negative")
+	}
+
+	unlock := tm.lock()
+	defer unlock()
+
+	indexes := make([]int, 0, len(ids))
+	for _, id := range ids {
+		i := tm.indexOf(id)
+		if i < 0 {
+			return nil, &NotFoundError{ID: id}
+		}
+		if err := changes.Validate(tm.tasks[i]); err != nil {
+			return nil, fmt.Errorf("task %d: %w", id, err)
+		}
+		indexes = append(indexes, i)
+	}
+
+	updated := make([]Task, 0, len(indexes))
+	for _, i := range indexes {
+		t := &tm.tasks[i]
+		if changes.Title != "" {
+			t.recordChange("title", t.Title, changes.Title)
+			t.Title = changes.Title
+		}
+		if changes.Priority != nil {
+			t.recordChange("priority", t.Priority.String(), changes.Priority.String())
+			t.Priority = *changes.Priority
+		}
+		if changes.SetTags {
+			tags := NormalizeTags(changes.Tags)
+			t.recordChange("tags", strings.Join(t.Tags, ","), strings.Join(tags, ","))
+			t.Tags = tags
+		}
+		if changes.Estimate != nil {
+			t.recordChange("estimate", formatEstimate(t.Estimate), formatEstimate(*changes.Estimate))
+			t.Estimate = *changes.Estimate
+		}
+		due, reminder := changes.times(*t)
+		t.recordChange("due_date", historyTime(t.DueDate), historyTime(due))
+		t.DueDate = cloneTime(due)
+		t.recordChange("reminder", historyTime(t.Reminder), historyTime(reminder))
+		t.Reminder = cloneTime(reminder)
+
+		action := ActionUpdated
+		if changes.Complete && !t.Done {
+			t.setDone(true, "")
+			action = ActionCompleted
+		}
+		t.UpdateTimeStatus()
+		tm.emit(action, *t)
+		updated = append(updated, t.Clone())
+	}
+	return updated, nil
+}
+
+// RaisePriority raises the priority of a pending task one level, when it
+// still has the priority from. It returns the raised task, false when the
+// task is gone, completed or had its priority changed.
+func (tm *TaskManager) RaisePriority(id int, from TaskPriority) (Task, bool) {
+	unlock := tm.lock()
+	defer unlock()
+
+	i := tm.indexOf(id)
+	if i < 0 || tm.tasks[i].Done || tm.tasks[i].Priority != from || from >= PriorityUrgent {
+		return Task{}, false
+	}
+	next := from + 1
+	tm.tasks[i].recordChange("priority", from.String(), next.String())
+	tm.tasks[i].Priority = next
+	tm.tasks[i].UpdateTimeStatus()
+	tm.emit(ActionUpdated, tm.tasks[i])
+	return tm.tasks[i].Clone(), true
+}
+
+// CompleteTask marks a task as done with an optional completion note
+func (tm *TaskManager) CompleteTask(id int, note string) error {
+	unlock := tm.lock()
+	defer unlock()
+
+	i := tm.indexOf(id)
+	if i < 0 {
+		return &NotFoundError{ID: id}
+	}
+	if tm.tasks[i].Done {
+		return errorf(ErrAlreadyCompleted, "task with ID %d is already completed", id)
+	}
+	tm.tasks[i].setDone(true, note)
+	tm.tasks[i].UpdateTimeStatus()
+	tm.emit(ActionCompleted, tm.tasks[i])
+	return nil
+}
+
+// ReopenTask marks a completed task as pending again, the note explains why
+func (tm *TaskManager) ReopenTask(id int, note string) error {
+	unlock := tm.lock()
+	defer unlock()
+
+	i := tm.indexOf(id)
+	if i < 0 {
+		return &NotFoundError{ID: id}
+	}
+	if !tm.tasks[i].Done {
+		return errorf(ErrNotCompleted, "task with ID %d is not completed", id)
+	}
+	tm.tasks[i].setDone(false, note)
+	tm.tasks[i].UpdateTimeStatus()
+	tm.emit(ActionReopened, tm.tasks[i])
+	return nil
 }
 
 // GetTaskByID returns a task by its ID
 func (tm *TaskManager) GetTaskByID(id int) (Task, error) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	i := tm.indexOf(id)
+	if i < 0 {
+		return Task{}, &NotFoundError{ID: id}
+	}
+	task := tm.tasks[i].Clone()
+	task.UpdateTimeStatus()
+	return task, nil
+}
+
+// DeleteTask moves a task to the trash, it can be restored later with RestoreTask
+func (tm *TaskManager) DeleteTask(id int) error {
+	unlock := tm.lock()
+	defer unlock()
+
+	i := tm.indexOf(id)
+	if i < 0 {
+		return &NotFoundError{ID: id}
+	}
+	now := time.Now()
+	tm.tasks[i].stopTimer()
+	tm.tasks[i].DeletedAt = &now
+	tm.tasks[i].setModified("deleted_at", now)
+	tm.tasks[i].addHistory(ActionDeleted, "")
+	tm.emit(ActionDeleted, tm.tasks[i])
+	return nil
+}
+
+// RestoreTask moves a task back from the trash
+func (tm *TaskManager) RestoreTask(id int) error {
+	unlock := tm.lock()
+	defer unlock()
+
+	for i, task := range tm.tasks {
+		if task.ID == id && task.IsDeleted() {
+			tm.tasks[i].DeletedAt = nil
+			tm.tasks[i].setModified("deleted_at", time.Now())
+			tm.tasks[i].addHistory(ActionRestored, "")
+			tm.tasks[i].UpdateTimeStatus()
+			tm.emit(ActionRestored, tm.tasks[i])
+			return nil
+		}
+	}
+	return &NotFoundError{ID: id, In: "trash"}
+}
+
+// GetTrashedTasks returns the tasks in the trash, most recently deleted first
+func (tm *TaskManager) GetTrashedTasks() []Task {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	var trashed []Task
 	for _, task := range tm.tasks {
-		if task.ID == id {
+		if task.IsDeleted() {
+			task = task.Clone()
 			task.UpdateTimeStatus()
-			return task, nil
+			trashed = append(trashed, task)
 		}
 	}
-	return Task{}, fmt.Errorf("task with ID %d not found", id)
+
+	sort.Slice(trashed, func(i, j int) bool {
+		return trashed[i].DeletedAt.After(*trashed[j].DeletedAt)
+	})
+
+	return trashed
 }
 
-// DeleteTask removes a task from the task manager
-func (tm *TaskManager) DeleteTask(id int) error {
-	for i, task := range tm.tasks {
+// EmptyTrash permanently removes the tasks deleted more than olderThan ago.
+// A zero duration removes every task in the trash. It returns the number of
+// removed tasks.
+func (tm *TaskManager) EmptyTrash(olderThan time.Duration) int {
+	unlock := tm.lock()
+	defer unlock()
+
+	cutoff := time.Now().Add(-olderThan)
+	kept := tm.tasks[:0]
+	removed := 0
+	for _, task := range tm.tasks {
+		if task.IsDeleted() && !task.DeletedAt.After(cutoff) {
+			removed++
+			tm.emit(ActionPurged, task)
+			continue
+		}
+		kept = append(kept, task)
+	}
+	tm.tasks = kept
+	return removed
+}
+
+// ArchiveTasks moves completed tasks to the archive. When ids is empty every
+// completed task finished more than olderThan ago is archived, otherwise only
+// the given tasks are, and they must be completed. It returns the archived tasks.
+func (tm *TaskManager) ArchiveTasks(ids []int, olderThan time.Duration) ([]Task, error) {
+	unlock := tm.lock()
+	defer unlock()
+
+	selected := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		i := tm.indexOf(id)
+		if i < 0 {
+			return nil, &NotFoundError{ID: id}
+		}
+		if !tm.tasks[i].Done {
+			return nil, errorf(ErrNotCompleted, "task with ID %d is not completed", id)
+		}
+		selected[id] = true
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	var archived []Task
+	kept := tm.tasks[:0]
+	for _, task := range tm.tasks {
+		archive := task.Done && !task.IsDeleted()
+		if len(ids) > 0 {
+			archive = archive && selected[task.ID]
+		} else {
+			archive = archive && !task.CompletedAt.After(cutoff)
+		}
+
+		if archive {
+			task.addHistory(ActionArchived, "")
+			tm.emit(ActionArchived, task)
+			archived = append(archived, task)
+			continue
+		}
+		kept = append(kept, task)
+	}
+	tm.tasks = kept
+	tm.archived = append(tm.archived, archived...)
+
+	return cloneTasks(archived), nil
+}
+
+// UnarchiveTask moves a task from the archive back to the active tasks
+func (tm *TaskManager) UnarchiveTask(id int) error {
+	unlock := tm.lock()
+	defer unlock()
+
+	for i, task := range tm.archived {
 		if task.ID == id {
-			tm.tasks = append(tm.tasks[:i], tm.tasks[i+1:]...)
+			tm.archived = append(tm.archived[:i], tm.archived[i+1:]...)
+			task.addHistory(ActionUnarchived, "")
+			task.UpdateTimeStatus()
+			tm.tasks = append(tm.tasks, task)
+			tm.unarchived = true
+			tm.emit(ActionUnarchived, task)
 			return nil
 		}
 	}
-	return fmt.Errorf("task with ID %d not found", id)
+	return &NotFoundError{ID: id, In: "archive"}
+}
+
+// GetArchivedTasks returns the archived tasks ordered by ID
+func (tm *TaskManager) GetArchivedTasks() []Task {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	archived := cloneTasks(tm.archived)
+
+	sort.Slice(archived, func(i, j int) bool {
+		return archived[i].ID < archived[j].ID
+	})
+
+	return archived
+}
+
+// indexOf returns the position of the task with the given ID, ignoring
+// tasks in the trash. It returns -1 if the task doesn't exist.
+func (tm *TaskManager) indexOf(id int) int {
+	for i, task := range tm.tasks {
+		if task.ID == id && !task.IsDeleted() {
+			return i
+		}
+	}
+	return -1
 }
@@ -0,0 +1,346 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestManager returns a task manager saving its files in a temporary
+// directory, with n tasks already added
+func newTestManager(t *testing.T, n int) *TaskManager {
+	t.Helper()
+	tm := NewTaskManagerAt(t.TempDir())
+	for i := 0; i < n; i++ {
+		tm.AddTask(fmt.Sprintf("task %d", i+1), DefaultPriority)
+	}
+	return tm
+}
+
+func TestConcurrentMutators(t *testing.T) {
+	const seeded, workers, rounds = 20, 8, 50
+	tm := newTestManager(t, seeded)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				id := (w*rounds+i)%seeded + 1
+				high := PriorityHigh
+				due := time.Now().Add(time.Duration(i) * time.Hour)
+
+				tm.AddTask(fmt.Sprintf("worker %d round %d", w, i), PriorityLow)
+				// Errors like completing a completed task are expected
+				// with several workers on the same tasks
+				tm.UpdateTask(id, fmt.Sprintf("renamed by %d", w), i%2 == 0, &high)
+				tm.SetTags(id, []string{"worker", fmt.Sprint(w)})
+				tm.SetDueDate(id, due)
+				tm.CompleteTask(id, "")
+				tm.ReopenTask(id, "")
+				tm.LogTime(id, time.Minute, "")
+				tm.GetTasksSorted(true, true)
+				if _, err := tm.GetTaskByID(id); err != nil {
+					t.Errorf("GetTaskByID(%d): %v", id, err)
+				}
+				if i%10 == 0 {
+					if err := tm.SaveTasks(); err != nil {
+						t.Errorf("SaveTasks: %v", err)
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	tasks := tm.GetTasksSorted(false, false)
+	if want := seeded + workers*rounds; len(tasks) != want {
+		t.Fatalf("got %d tasks, want %d", len(tasks), want)
+	}
+	ids := make(map[int]bool)
+	for _, task := range tasks {
+		if ids[task.ID] {
+			t.Fatalf("task ID %d used twice", task.ID)
+		}
+		ids[task.ID] = true
+	}
+
+	// The files hold every task once the last save is done
+	if err := tm.SaveTasks(); err != nil {
+		t.Fatalf("SaveTasks: %v", err)
+	}
+	loaded := NewTaskManagerAt(tm.dir)
+	if err := loaded.LoadTasks(); err != nil {
+		t.Fatalf("LoadTasks: %v", err)
+	}
+	if got := len(loaded.GetTasksSorted(false, false)); got != len(tasks) {
+		t.Fatalf("loaded %d tasks, want %d", got, len(tasks))
+	}
+}
+
+func TestClone(t *testing.T) {
+	due := time.Date(2030, 1, 10, 15, 0, 0, 0, time.UTC)
+	end := due.Add(-time.Hour)
+	original := Task{
+		ID:          1,
+		Title:       "original",
+		Tags:        []string{"a", "b"},
+		DueDate:     &due,
+		Reminder:    &end,
+		TimeEntries: []TimeEntry{{Start: end.Add(-time.Hour), End: &end}},
+		History:     []HistoryEntry{{Action: ActionCreated, At: end}},
+		Modified:    map[string]time.Time{"title": end},
+	}
+
+	clone := original.Clone()
+	clone.Tags[0] = "changed"
+	*clone.DueDate = clone.DueDate.Add(time.Hour)
+	*clone.Reminder = clone.Reminder.Add(time.Hour)
+	*clone.TimeEntries[0].End = clone.TimeEntries[0].End.Add(time.Hour)
+	clone.History[0].Action = ActionUpdated
+	clone.Modified["title"] = due
+
+	if original.Tags[0] != "a" {
+		t.Errorf("tags changed through the clone: %v", original.Tags)
+	}
+	if !original.DueDate.Equal(due) {
+		t.Errorf("due date changed through the clone: %v", original.DueDate)
+	}
+	if !original.Reminder.Equal(end) || !original.TimeEntries[0].End.Equal(end) {
+		t.Errorf("times changed through the clone: %v, %v", original.Reminder, original.TimeEntries[0].End)
+	}
+	if original.History[0].Action != ActionCreated {
+		t.Errorf("history changed through the clone: %v", original.History)
+	}
+	if !original.Modified["title"].Equal(end) {
+		t.Errorf("modified times changed through the clone: %v", original.Modified)
+	}
+}
+
+func TestReturnedTasksAreCopies(t *testing.T) {
+	tm := newTestManager(t, 1)
+	if err := tm.SetTags(1, []string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.SetDueDate(1, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := tm.GetTaskByID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.Tags[0] = "changed"
+	*got.DueDate = time.Time{}
+	tm.GetTasksSorted(false, false)[0].Tags[0] = "changed"
+
+	again, err := tm.GetTaskByID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Tags[0] != "a" || again.DueDate.IsZero() {
+		t.Errorf("task changed through a returned copy: %v, %v", again.Tags, again.DueDate)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	tm := newTestManager(t, 0)
+
+	var actions []string
+	unsubscribe := tm.Subscribe(func(e Event) {
+		// Subscribers can use the task manager
+		tm.GetTasksSorted(false, false)
+		actions = append(actions, e.Action)
+	})
+
+	created := tm.AddTask("subscribed", DefaultPriority)
+	if err := tm.SetTags(created.ID, []string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.UpdateTask(created.ID, "", true, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.UpdateTask(created.ID, "", false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.DeleteTask(created.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{ActionCreated, ActionUpdated, ActionCompleted, ActionReopened, ActionDeleted}
+	if fmt.Sprint(actions) != fmt.Sprint(want) {
+		t.Errorf("got events %v, want %v", actions, want)
+	}
+
+	unsubscribe()
+	tm.AddTask("unsubscribed", DefaultPriority)
+	if len(actions) != len(want) {
+		t.Errorf("got events after unsubscribing: %v", actions[len(want):])
+	}
+}
+
+func TestSubscribeConcurrent(t *testing.T) {
+	const workers, rounds = 8, 50
+	tm := newTestManager(t, 0)
+
+	var mu sync.Mutex
+	events := 0
+	seen := make(map[int]bool)
+	tm.Subscribe(func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events++
+		seen[e.Task.ID] = true
+	})
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				task := tm.AddTask("concurrent", DefaultPriority)
+				if err := tm.CompleteTask(task.ID, ""); err != nil {
+					t.Errorf("CompleteTask(%d): %v", task.ID, err)
+				}
+			}
+		}()
+	}
+
+	// Subscribing and unsubscribing while the tasks change
+	for i := 0; i < rounds; i++ {
+		tm.Subscribe(func(Event) {})()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := 2 * workers * rounds; events != want {
+		t.Errorf("got %d events, want %d", events, want)
+	}
+	if len(seen) != workers*rounds {
+		t.Errorf("got events for %d tasks, want %d", len(seen), workers*rounds)
+	}
+}
+
+func TestUpdateTasks(t *testing.T) {
+	now := time.Now()
+	soon, later := now.Add(time.Hour), now.Add(48*time.Hour)
+	high := PriorityHigh
+
+	tests := []struct {
+		name    string
+		ids     []int
+		changes TaskChanges
+		wantErr error
+	}{
+		{"priority and tags", []int{1, 2, 3}, TaskChanges{Priority: &high, SetTags: true, Tags: []string{"#Bulk"}}, nil},
+		{"complete", []int{1, 3}, TaskChanges{Complete: true}, nil},
+		{"missing task", []int{1, 9}, TaskChanges{Priority: &high}, ErrNotFound},
+		// The reminder is after the due date of task 2 only
+		{"invalid for one task", []int{1, 2, 3}, TaskChanges{Priority: &high, Reminder: &later}, ErrInvalidTimeOrder},
+		{"estimate removed", []int{1}, TaskChanges{Estimate: new(time.Duration)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := newTestManager(t, 3)
+			if err := tm.SetDueDate(2, soon); err != nil {
+				t.Fatal(err)
+			}
+			before := tm.GetTasksSorted(false, false)
+
+			var events []Event
+			tm.Subscribe(func(e Event) { events = append(events, e) })
+
+			updated, err := tm.UpdateTasks(tt.ids, tt.changes)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				// No task changed
+				if after := tm.GetTasksSorted(false, false); !reflect.DeepEqual(after, before) {
+					t.Errorf("tasks changed by a failed update:\n%v\n%v", before, after)
+				}
+				if len(events) > 0 {
+					t.Errorf("got events for a failed update: %v", events)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(updated) != len(tt.ids) || len(events) != len(tt.ids) {
+				t.Fatalf("got %d tasks and %d events, want %d", len(updated), len(events), len(tt.ids))
+			}
+			for i, got := range updated {
+				if tt.changes.Priority != nil && got.Priority != *tt.changes.Priority {
+					t.Errorf("task %d has priority %v", got.ID, got.Priority)
+				}
+				if tt.changes.SetTags && fmt.Sprint(got.Tags) != "[bulk]" {
+					t.Errorf("task %d has tags %v", got.ID, got.Tags)
+				}
+				if got.Done != tt.changes.Complete {
+					t.Errorf("task %d done = %v", got.ID, got.Done)
+				}
+				want := ActionUpdated
+				if tt.changes.Complete {
+					want = ActionCompleted
+				}
+				if events[i].Action != want {
+					t.Errorf("got event %s for task %d, want %s", events[i].Action, got.ID, want)
+				}
+			}
+		})
+	}
+
+	tm := newTestManager(t, 1)
+	negative := -time.Hour
+	if _, err := tm.UpdateTasks([]int{1}, TaskChanges{Estimate: &negative}); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("got error %v for a negative estimate, want %v", err, ErrInvalidValue)
+	}
+}
+
+func TestImportTaskUID(t *testing.T) {
+	tm := newTestManager(t, 0)
+	for _, uid := range []string{"active", "trashed", "archived"} {
+		if _, err := tm.ImportTask(Task{UID: uid, Title: uid, Done: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tm.DeleteTask(2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tm.ArchiveTasks([]int{3}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		uid     string
+		wantErr bool
+	}{
+		{"active", true},
+		{"trashed", true},
+		{"archived", true},
+		{"unused", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		imported, err := tm.ImportTask(Task{UID: tt.uid, Title: "imported"})
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidValue) {
+				t.Errorf("ImportTask with UID %q: got error %v, want %v", tt.uid, err, ErrInvalidValue)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ImportTask with UID %q: %v", tt.uid, err)
+		} else if imported.UID == "" {
+			t.Errorf("ImportTask with UID %q: no UID generated", tt.uid)
+		}
+	}
+}
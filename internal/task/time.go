@@ -2,6 +2,8 @@ package task
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -50,7 +52,10 @@ func (ts TimeStatus) Color() string {
 	}
 }
 
-// ParseDateTime parses a string and returns the corresponding time.Time
+// ParseDateTime parses a string and returns the corresponding time.Time.
+// Due dates and reminders are wall-clock times: the date and time of day
+// as written, whatever the time zone, kept in the UTC location. See
+// WallClock for times that come with a zone.
 func ParseDateTime(s string) (time.Time, error) {
 	// multiple date time formats
 	formats := []string{
@@ -59,6 +64,9 @@ func ParseDateTime(s string) (time.Time, error) {
 		"2006/01/02 15:04",
 		"02/01/2006 15:04",
 		"02-01-2006 15:04",
+		"2006-01-02",
+		"2006/01/02",
+		"02/01/2006",
 	}
 
 	var firstErr error
@@ -69,7 +77,14 @@ func ParseDateTime(s string) (time.Time, error) {
 			firstErr = err
 		}
 	}
-	return time.Time{}, fmt.Errorf("invalid date time format: %v", firstErr)
+	return time.Time{}, fmt.Errorf("%w: %v", ErrInvalidDate, firstErr)
+}
+
+// WallClock returns the date and time of day of t in its own location as a
+// wall-clock time, the form ParseDateTime returns. Convert t to the location
+// it is meant for first, like t.In(time.Local).
+func WallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
 }
 
 // FormatDateTime returns a formatted string representation of a time.Time
@@ -114,8 +129,34 @@ func ValidateTimeOrder(dueDate, reminder *time.Time) error {
 	}
 
 	if reminder.After(*dueDate) {
-		return fmt.Errorf("reminder time cannot be after due date")
+		return ErrInvalidTimeOrder
 	}
 
 	return nil
 }
+
+// ParseDuration parses a duration string like time.ParseDuration does, but
+// also accepts days ("30d") and weeks ("2w") as units. Negative durations
+// are rejected
+func ParseDuration(s string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+
+	for suffix, unit := range units {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			value, err := strconv.Atoi(n)
+			if err != nil || value < 0 {
+				return 0, fmt.Errorf("%w: %s", ErrInvalidDuration, s)
+			}
+			return time.Duration(value) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidDuration, s)
+	}
+	return d, nil
+}
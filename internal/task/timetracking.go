@@ -0,0 +1,186 @@
+package task
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeEntry is a period of time spent working on a task. Entries of a
+// running timer have no end.
+type TimeEntry struct {
+	Start  time.Time  `json:"start"`
+	End    *time.Time `json:"end,omitempty"`
+	Manual bool       `json:"manual,omitempty"`
+	Note   string     `json:"note,omitempty"`
+}
+
+// Duration returns the length of the entry, running entries count until now
+func (e TimeEntry) Duration() time.Duration {
+	if e.End == nil {
+		return time.Since(e.Start)
+	}
+	return e.End.Sub(e.Start)
+}
+
+// TrackedTime returns the total time logged on the task
+func (t *Task) TrackedTime() time.Duration {
+	var total time.Duration
+	for _, e := range t.TimeEntries {
+		total += e.Duration()
+	}
+	return total
+}
+
+// IsTimerRunning shows if there is a running timer on the task
+func (t *Task) IsTimerRunning() bool {
+	n := len(t.TimeEntries)
+	return n > 0 && t.TimeEntries[n-1].End == nil
+}
+
+// stopTimer stops the running timer of the task, if any
+func (t *Task) stopTimer() bool {
+	if !t.IsTimerRunning() {
+		return false
+	}
+	now := time.Now()
+	last := &t.TimeEntries[len(t.TimeEntries)-1]
+	last.End = &now
+	t.addHistory(ActionTimerStopped, FormatDuration(last.Duration()))
+	return true
+}
+
+// StartTimer starts tracking time on a task. Only one timer can run at a time.
+func (tm *TaskManager) StartTimer(id int) error {
+	unlock := tm.lock()
+	defer unlock()
+
+	if active, ok := tm.activeTimer(); ok {
+		return fmt.Errorf("%w on task %d", ErrTimerRunning, active.ID)
+	}
+
+	i := tm.indexOf(id)
+	if i < 0 {
+		return &NotFoundError{ID: id}
+	}
+	if tm.tasks[i].Done {
+		return errorf(ErrAlreadyCompleted, "task with ID %d is already completed", id)
+	}
+
+	tm.tasks[i].TimeEntries = append(tm.tasks[i].TimeEntries, TimeEntry{Start: time.Now()})
+	tm.tasks[i].addHistory(ActionTimerStarted, "")
+	tm.emit(ActionTimerStarted, tm.tasks[i])
+	return nil
+}
+
+// StopTimer stops the running timer and returns the task it was running on
+func (tm *TaskManager) StopTimer() (Task, error) {
+	unlock := tm.lock()
+	defer unlock()
+
+	for i := range tm.tasks {
+		if !tm.tasks[i].IsDeleted() && tm.tasks[i].stopTimer() {
+			tm.emit(ActionTimerStopped, tm.tasks[i])
+			return tm.tasks[i].Clone(), nil
+		}
+	}
+	return Task{}, ErrNoTimer
+}
+
+// ActiveTimer returns the task with a running timer, if any
+func (tm *TaskManager) ActiveTimer() (Task, bool) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	task, ok := tm.activeTimer()
+	return task.Clone(), ok
+}
+
+// activeTimer returns the task with a running timer without locking
+func (tm *TaskManager) activeTimer() (Task, bool) {
+	for _, task := range tm.tasks {
+		if !task.IsDeleted() && task.IsTimerRunning() {
+			return task, true
+		}
+	}
+	return Task{}, false
+}
+
+// LogTime adds a manual time entry of the given duration ending now
+func (tm *TaskManager) LogTime(id int, d time.Duration, note string) error {
+	if d <= 0 {
+		return errorf(ErrInvalidValue, "logged time must be positive")
+	}
+
+	unlock := tm.lock()
+	defer unlock()
+
+	i := tm.indexOf(id)
+	if i < 0 {
+		return &NotFoundError{ID: id}
+	}
+
+	end := time.Now()
+	entry := TimeEntry{
+		Start:  end.Add(-d),
+		End:    &end,
+		Manual: true,
+		Note:   note,
+	}
+
+	// Keep the running entry, if any, as the last one
+	entries := tm.tasks[i].TimeEntries
+	if tm.tasks[i].IsTimerRunning() {
+		running := entries[len(entries)-1]
+		entries = append(entries[:len(entries)-1], entry, running)
+	} else {
+		entries = append(entries, entry)
+	}
+	tm.tasks[i].TimeEntries = entries
+	tm.tasks[i].addHistory(ActionTimeLogged, FormatDuration(d))
+	tm.emit(ActionTimeLogged, tm.tasks[i])
+	return nil
+}
+
+// SetEstimate sets the estimated effort of a task, zero removes it
+func (tm *TaskManager) SetEstimate(id int, estimate time.Duration) error {
+	if estimate < 0 {
+		return errorf(ErrInvalidValue, "estimate can't be negative")
+	}
+
+	unlock := tm.lock()
+	defer unlock()
+
+	i := tm.indexOf(id)
+	if i < 0 {
+		return &NotFoundError{ID: id}
+	}
+
+	tm.tasks[i].recordChange("estimate", formatEstimate(tm.tasks[i].Estimate), formatEstimate(estimate))
+	tm.tasks[i].Estimate = estimate
+	tm.emit(ActionUpdated, tm.tasks[i])
+	return nil
+}
+
+// FormatDuration returns a short representation of a duration like "1h30m"
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dh%02dm", hours, minutes)
+	}
+}
+
+// formatEstimate formats an estimate for the history, zero means no estimate
+func formatEstimate(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return FormatDuration(d)
+}
@@ -0,0 +1,134 @@
+package task
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// BlockedTag is the tag of the tasks that can't be worked on yet
+const BlockedTag = "blocked"
+
+// UrgencyWeights are the coefficients of the urgency score. The score of a
+// pending task is the sum of:
+//
+//   - the weight of its priority
+//   - Due times how close the due date is, from 0.2 for two weeks or more
+//     away to 1 for a week or more overdue
+//   - Age times its age over AgeMax, up to 1
+//   - Blocked when it has the blocked tag
+//   - the weights of its tags
+type UrgencyWeights struct {
+	Priority map[TaskPriority]float64
+	Due      float64
+	Age      float64
+	AgeMax   time.Duration
+	Blocked  float64
+	Tags     map[string]float64
+}
+
+// DefaultUrgencyWeights returns the weights used when the configuration
+// doesn't change them
+func DefaultUrgencyWeights() UrgencyWeights {
+	return UrgencyWeights{
+		Priority: map[TaskPriority]float64{
+			PriorityNone:    0,
+			PrioritySomeday: -2,
+			PriorityLow:     1.8,
+			PriorityMedium:  3.9,
+			PriorityHigh:    6,
+			PriorityUrgent:  9,
+		},
+		Due:     12,
+		Age:     2,
+		AgeMax:  365 * 24 * time.Hour,
+		Blocked: -5,
+		Tags:    map[string]float64{},
+	}
+}
+
+// urgencyWeights are the weights of the urgency score, the configuration
+// can replace them with SetUrgencyWeights
+var urgencyWeights = DefaultUrgencyWeights()
+
+// SetUrgencyWeights replaces the weights of the urgency score
+func SetUrgencyWeights(w UrgencyWeights) {
+	urgencyWeights = w
+}
+
+// UrgencyTerm is a part of the urgency score of a task
+type UrgencyTerm struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+}
+
+// UrgencyTerms returns the parts of the urgency score of a task that
+// aren't zero. Completed and deleted tasks have none.
+func (t *Task) UrgencyTerms(now time.Time) []UrgencyTerm {
+	if t.Done || t.IsDeleted() {
+		return nil
+	}
+	w := urgencyWeights
+
+	var terms []UrgencyTerm
+	add := func(name string, value float64) {
+		if value != 0 {
+			terms = append(terms, UrgencyTerm{Name: name, Value: value})
+		}
+	}
+
+	add("priority", w.Priority[t.Priority])
+	if t.DueDate != nil {
+		add("due", w.Due*dueFactor(t.DueDate.Sub(now)))
+	}
+	if w.AgeMax > 0 && !t.CreatedAt.IsZero() {
+		add("age", w.Age*math.Min(float64(now.Sub(t.CreatedAt))/float64(w.AgeMax), 1))
+	}
+	for _, tag := range t.Tags {
+		if tag == BlockedTag {
+			add("blocked", w.Blocked)
+		}
+		add("tag "+tag, w.Tags[tag])
+	}
+	return terms
+}
+
+// Urgency returns the urgency score of a task, higher is more urgent
+func (t *Task) Urgency(now time.Time) float64 {
+	score := 0.0
+	for _, term := range t.UrgencyTerms(now) {
+		score += term.Value
+	}
+	return score
+}
+
+// IsBlocked shows if the task can't be worked on yet
+func (t *Task) IsBlocked() bool {
+	return t.HasTag(BlockedTag)
+}
+
+// dueFactor maps the time left until the due date to 0.2-1: 1 for a week
+// or more overdue, 0.2 for two weeks or more away, linear in between
+func dueFactor(left time.Duration) float64 {
+	days := left.Hours() / 24
+	switch {
+	case days <= -7:
+		return 1
+	case days >= 14:
+		return 0.2
+	default:
+		return 1 - 0.8*(days+7)/21
+	}
+}
+
+// SortByUrgency sorts the tasks from the most urgent, keeping the order of
+// the tasks with the same score
+func SortByUrgency(tasks []Task, now time.Time) {
+	scores := make(map[int]float64, len(tasks))
+	for _, t := range tasks {
+		scores[t.ID] = t.Urgency(now)
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return scores[tasks[i].ID] > scores[tasks[j].ID]
+	})
+}
@@ -0,0 +1,109 @@
+package task
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDueFactor(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		left time.Duration
+		want float64
+	}{
+		{-30 * day, 1},
+		{-7 * day, 1},
+		{0, 1 - 0.8*7.0/21},
+		{7 * day, 1 - 0.8*14.0/21},
+		{14 * day, 0.2},
+		{60 * day, 0.2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.left.String(), func(t *testing.T) {
+			if got := dueFactor(tt.left); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUrgencyTerms(t *testing.T) {
+	defer SetUrgencyWeights(urgencyWeights)
+	w := DefaultUrgencyWeights()
+	w.Tags = map[string]float64{"work": 1.5}
+	SetUrgencyWeights(w)
+
+	now := time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)
+	overdue := now.Add(-10 * 24 * time.Hour)
+
+	tests := []struct {
+		name string
+		task Task
+		want map[string]float64
+	}{
+		{"priority only", Task{Priority: PriorityHigh}, map[string]float64{"priority": 6}},
+		{"no priority", Task{Priority: PriorityNone}, map[string]float64{}},
+		{"overdue", Task{Priority: PriorityLow, DueDate: &overdue}, map[string]float64{"priority": 1.8, "due": 12}},
+		{"half the maximum age", Task{Priority: PriorityNone, CreatedAt: now.Add(-w.AgeMax / 2)}, map[string]float64{"age": 1}},
+		{"older than the maximum age", Task{Priority: PriorityNone, CreatedAt: now.Add(-2 * w.AgeMax)}, map[string]float64{"age": 2}},
+		{"blocked", Task{Priority: PriorityNone, Tags: []string{BlockedTag}}, map[string]float64{"blocked": -5}},
+		{"weighted tag", Task{Priority: PriorityNone, Tags: []string{"work", "home"}}, map[string]float64{"tag work": 1.5}},
+		{"completed", Task{Priority: PriorityUrgent, Done: true}, map[string]float64{}},
+		{"deleted", Task{Priority: PriorityUrgent, DeletedAt: &overdue}, map[string]float64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terms := tt.task.UrgencyTerms(now)
+			got := make(map[string]float64, len(terms))
+			total := 0.0
+			for _, term := range terms {
+				got[term.Name] = term.Value
+				total += term.Value
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got terms %v, want %v", got, tt.want)
+			}
+			for name, want := range tt.want {
+				if math.Abs(got[name]-want) > 1e-9 {
+					t.Errorf("got %s %v, want %v", name, got[name], want)
+				}
+			}
+			if score := tt.task.Urgency(now); math.Abs(score-total) > 1e-9 {
+				t.Errorf("got urgency %v, want the sum of the terms %v", score, total)
+			}
+		})
+	}
+}
+
+func TestSortByUrgency(t *testing.T) {
+	now := time.Date(2030, 6, 1, 12, 0, 0, 0, time.UTC)
+	due := now.Add(24 * time.Hour)
+	tasks := []Task{
+		{ID: 1, Priority: PriorityLow},
+		{ID: 2, Priority: PriorityHigh},
+		{ID: 3, Priority: PriorityLow},
+		{ID: 4, Priority: PriorityLow, DueDate: &due},
+		{ID: 5, Priority: PrioritySomeday},
+		{ID: 6, Priority: PriorityUrgent, Tags: []string{BlockedTag}},
+	}
+
+	SortByUrgency(tasks, now)
+
+	// Ties keep their order
+	want := []int{4, 2, 6, 1, 3, 5}
+	for i, task := range tasks {
+		if task.ID != want[i] {
+			t.Fatalf("got order %v, want %v", ids(tasks), want)
+		}
+	}
+}
+
+// ids returns the IDs of the tasks in order
+func ids(tasks []Task) []int {
+	var ids []int
+	for _, t := range tasks {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
@@ -0,0 +1,71 @@
+// Package taskcli gives Go programs access to the tasks of the task command
+// line tool, stored as JSON files in a data directory (~/.task-cli by
+// default). The command line opens its tasks through it.
+//
+// Open a data directory and change its tasks with a Manager. Changes stay
+// in memory until they are saved:
+//
+//	ctx := context.Background()
+//	m, err := taskcli.Open(ctx, "") // "" is the default data directory
+//	if err != nil {
+//		return err
+//	}
+//
+//	t, err := m.Add(ctx, "Write the release notes", taskcli.PriorityHigh)
+//	if err != nil {
+//		return err
+//	}
+//	due, _ := taskcli.ParseDateTime("2024-01-10 15:00")
+//	if err := m.SetDueDate(ctx, t.ID, due); err != nil {
+//		return err
+//	}
+//	if err := m.Save(ctx); err != nil {
+//		return err
+//	}
+//
+// List selects tasks with the same options as the list command, including
+// filter expressions:
+//
+//	tasks, err := m.List(ctx, taskcli.ListOptions{
+//		Where:      "tag:backend status:pending",
+//		ByPriority: true,
+//	})
+//
+// Operations on a task that doesn't exist fail with an error that matches
+// ErrNotFound:
+//
+//	if err := m.Complete(ctx, 42, ""); errors.Is(err, taskcli.ErrNotFound) {
+//		fmt.Println("no task 42")
+//	}
+//
+// The other errors match the ErrInvalid* values for invalid input, and
+// ErrAlreadyCompleted, ErrNotCompleted, ErrTimerRunning or ErrNoTimer when
+// the state of the task doesn't allow the operation.
+//
+// Subscribe reports every change, from any goroutine, once it is done:
+//
+//	cancel := m.Subscribe(func(e taskcli.Event) {
+//		fmt.Printf("task %d %s\n", e.Task.ID, e.Action)
+//	})
+//	defer cancel()
+//
+// Tasks returned by the library are copies, changing them doesn't change
+// the stored tasks.
+//
+// A Presenter shows the tasks the way the command line does, to any
+// writer:
+//
+//	p := taskcli.NewPresenter(os.Stdout, taskcli.PresenterOptions{Color: taskcli.ColorAuto})
+//	tasks, _ := m.List(ctx, taskcli.ListOptions{ByUrgency: true})
+//	p.PrintTaskTable(tasks)
+//
+// # Stability
+//
+// The package is not stable yet. Its types are aliases of the internal
+// types the command line is written with, not types of its own, so any
+// change to those internal types changes this API too: fields, methods and
+// the methods of ITaskManager and Presenter can change in any release.
+// Only Open and the Manager methods are meant to keep their signatures,
+// and even they may change until the package has types of its own.
+// Pin the version you build against.
+package taskcli
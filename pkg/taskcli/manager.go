@@ -0,0 +1,262 @@
+package taskcli
+
+import (
+	"context"
+	"github.com/kubaliski/task-cli/internal/task"
+	"time"
+)
+
+// Manager gives access to the tasks of a data directory. Every method checks
+// its context before doing anything, and is safe for concurrent use.
+// Changes are kept in memory until Save is called.
+type Manager struct {
+	tm *task.TaskManager
+}
+
+// Open loads the tasks stored in dir, the default data directory when dir
+// is empty. A directory without tasks opens with no tasks.
+func Open(ctx context.Context, dir string) (*Manager, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tm := task.NewTaskManagerAt(dir)
+	if err := tm.LoadTasks(); err != nil {
+		return nil, err
+	}
+	return &Manager{tm: tm}, nil
+}
+
+// TaskManager returns the underlying task manager, for the code written
+// against ITaskManager like the CLI commands
+func (m *Manager) TaskManager() *TaskManager {
+	return m.tm
+}
+
+// Save writes the tasks to the data directory
+func (m *Manager) Save(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.tm.SaveTasks()
+}
+
+// Subscribe registers a function called after every change, see
+// TaskManager.Subscribe. The returned function cancels the subscription.
+func (m *Manager) Subscribe(fn func(Event)) func() {
+	return m.tm.Subscribe(fn)
+}
+
+// Add creates a new task
+func (m *Manager) Add(ctx context.Context, title string, priority TaskPriority) (Task, error) {
+	if err := ctx.Err(); err != nil {
+		return Task{}, err
+	}
+	return m.tm.AddTask(title, priority), nil
+}
+
+// Import adds a task created elsewhere, it gets a new ID
+func (m *Manager) Import(ctx context.Context, t Task) (Task, error) {
+	if err := ctx.Err(); err != nil {
+		return Task{}, err
+	}
+	return m.tm.ImportTask(t)
+}
+
+// Get returns a task by its ID
+func (m *Manager) Get(ctx context.Context, id int) (Task, error) {
+	if err := ctx.Err(); err != nil {
+		return Task{}, err
+	}
+	return m.tm.GetTaskByID(id)
+}
+
+// List returns the tasks selected by the options, like the list command
+func (m *Manager) List(ctx context.Context, opts ListOptions) ([]Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return task.ListTasks(m.tm, opts)
+}
+
+// Update changes the title (when not empty), the completion status and the
+// priority (when not nil) of a task
+func (m *Manager) Update(ctx context.Context, id int, title string, done bool, priority *TaskPriority) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.tm.UpdateTask(id, title, done, priority)
+}
+
+// UpdateTasks makes the same changes to several tasks, either all of them
+// change or none does
+func (m *Manager) UpdateTasks(ctx context.Context, ids []int, changes TaskChanges) ([]Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return m.tm.UpdateTasks(ids, changes)
+}
+
+// SetTags replaces the tags of a task
+func (m *Manager) SetTags(ctx context.Context, id int, tags []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.tm.SetTags(id, tags)
+}
+
+// Complete marks a task as done with an optional note
+func (m *Manager) Complete(ctx context.Context, id int, note string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.tm.CompleteTask(id, note)
+}
+
+// Reopen marks a completed task as pending again
+func (m *Manager) Reopen(ctx context.Context, id int, note string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.tm.ReopenTask(id, note)
+}
+
+// SetDueDate sets the due date of a task
+func (m *Manager) SetDueDate(ctx context.Context, id int, due time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.tm.SetDueDate(id, due)
+}
+
+// RemoveDueDate removes the due date of a task
+func (m *Manager) RemoveDueDate(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.tm.RemoveDueDate(id)
+}
+
+// SetReminder sets the reminder of a task
+func (m *Manager) SetReminder(ctx context.Context, id int, reminder time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.tm.SetReminder(id, reminder)
+}
+
+// RemoveReminder removes the reminder of a task
+func (m *Manager) RemoveReminder(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.tm.RemoveReminder(id)
+}
+
+// Delete moves a task to the trash
+func (m *Manager) Delete(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.tm.DeleteTask(id)
+}
+
+// Restore moves a task back from the trash
+func (m *Manager) Restore(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.tm.RestoreTask(id)
+}
+
+// Trash returns the tasks in the trash, most recently deleted first
+func (m *Manager) Trash(ctx context.Context) ([]Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return m.tm.GetTrashedTasks(), nil
+}
+
+// EmptyTrash permanently removes the tasks deleted more than olderThan ago,
+// all of them when it is zero, and returns how many were removed
+func (m *Manager) EmptyTrash(ctx context.Context, olderThan time.Duration) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return m.tm.EmptyTrash(olderThan), nil
+}
+
+// Archive moves the given completed tasks to the archive, or every task
+// completed more than olderThan ago when ids is empty
+func (m *Manager) Archive(ctx context.Context, ids []int, olderThan time.Duration) ([]Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return m.tm.ArchiveTasks(ids, olderThan)
+}
+
+// Unarchive moves a task from the archive back to the active tasks
+func (m *Manager) Unarchive(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.tm.UnarchiveTask(id)
+}
+
+// Archived returns the archived tasks
+func (m *Manager) Archived(ctx context.Context) ([]Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return m.tm.GetArchivedTasks(), nil
+}
+
+// StartTimer starts tracking time on a task
+func (m *Manager) StartTimer(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.tm.StartTimer(id)
+}
+
+// StopTimer stops the running timer and returns its task
+func (m *Manager) StopTimer(ctx context.Context) (Task, error) {
+	if err := ctx.Err(); err != nil {
+		return Task{}, err
+	}
+	return m.tm.StopTimer()
+}
+
+// ActiveTimer returns the task with a running timer, if any
+func (m *Manager) ActiveTimer(ctx context.Context) (Task, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return Task{}, false, err
+	}
+	t, ok := m.tm.ActiveTimer()
+	return t, ok, nil
+}
+
+// LogTime records time spent on a task, ending now
+func (m *Manager) LogTime(ctx context.Context, id int, d time.Duration, note string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.tm.LogTime(id, d, note)
+}
+
+// SetEstimate sets the estimated effort of a task, zero removes it
+func (m *Manager) SetEstimate(ctx context.Context, id int, estimate time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.tm.SetEstimate(id, estimate)
+}
+
+// Merge merges the tasks saved by another copy of the data, base is their
+// common version or nil when unknown
+func (m *Manager) Merge(ctx context.Context, base, other []Task) (MergeResult, error) {
+	if err := ctx.Err(); err != nil {
+		return MergeResult{}, err
+	}
+	return m.tm.Merge(base, other), nil
+}
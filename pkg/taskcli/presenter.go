@@ -0,0 +1,37 @@
+package taskcli
+
+import (
+	"github.com/kubaliski/task-cli/internal/commands"
+	"io"
+)
+
+// Presenter shows tasks and messages the way the CLI commands do
+type Presenter = commands.Presenter
+
+// DefaultPresenter is the presenter of the CLI: tables, lists, task details
+// and JSON
+type DefaultPresenter = commands.DefaultPresenter
+
+// PresenterOptions are the color and format of a presenter, the --color and
+// --format options of the CLI. The other fields are ignored.
+type PresenterOptions = commands.Options
+
+// Presenter colors
+const (
+	ColorAuto   = commands.ColorAuto
+	ColorAlways = commands.ColorAlways
+	ColorNever  = commands.ColorNever
+)
+
+// Presenter formats, empty for the default of each command
+const (
+	FormatTable = commands.FormatTable
+	FormatList  = commands.FormatList
+	FormatJSON  = commands.FormatJSON
+)
+
+// NewPresenter creates a presenter writing to w. With ColorAuto the output
+// is colored when w is a terminal and NO_COLOR is not set.
+func NewPresenter(w io.Writer, opts PresenterOptions) *DefaultPresenter {
+	return commands.NewPresenterTo(w, opts)
+}
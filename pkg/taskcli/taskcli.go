@@ -0,0 +1,191 @@
+package taskcli
+
+import (
+	"github.com/kubaliski/task-cli/internal/task"
+	"time"
+)
+
+// Task model
+type (
+	// Task is a task with its dates, tags, tracked time and history
+	Task = task.Task
+	// TaskPriority is the priority of a task
+	TaskPriority = task.TaskPriority
+	// TimeStatus tells whether a task is overdue, due soon or has an
+	// upcoming reminder
+	TimeStatus = task.TimeStatus
+	// HistoryEntry is an event in the life of a task
+	HistoryEntry = task.HistoryEntry
+	// TimeEntry is a period of time spent working on a task
+	TimeEntry = task.TimeEntry
+)
+
+// Task priorities
+const (
+	PriorityNone    = task.PriorityNone
+	PrioritySomeday = task.PrioritySomeday
+	PriorityLow     = task.PriorityLow
+	PriorityMedium  = task.PriorityMedium
+	PriorityHigh    = task.PriorityHigh
+	PriorityUrgent  = task.PriorityUrgent
+	DefaultPriority = task.DefaultPriority
+)
+
+// Time statuses
+const (
+	TimeStatusNormal   = task.TimeStatusNormal
+	TimeStatusUpcoming = task.TimeStatusUpcoming
+	TimeStatusDueSoon  = task.TimeStatusDueSoon
+	TimeStatusOverdue  = task.TimeStatusOverdue
+)
+
+// History and event actions
+const (
+	ActionCreated      = task.ActionCreated
+	ActionImported     = task.ActionImported
+	ActionUpdated      = task.ActionUpdated
+	ActionCompleted    = task.ActionCompleted
+	ActionReopened     = task.ActionReopened
+	ActionDeleted      = task.ActionDeleted
+	ActionRestored     = task.ActionRestored
+	ActionArchived     = task.ActionArchived
+	ActionUnarchived   = task.ActionUnarchived
+	ActionTimerStarted = task.ActionTimerStarted
+	ActionTimerStopped = task.ActionTimerStopped
+	ActionTimeLogged   = task.ActionTimeLogged
+	ActionPurged       = task.ActionPurged
+	ActionMerged       = task.ActionMerged
+)
+
+// Task managers
+type (
+	// ITaskManager is the interface of the task managers used by the CLI
+	ITaskManager = task.ITaskManager
+	// TaskManager holds the tasks and stores them in a data directory. It
+	// is safe for concurrent use.
+	TaskManager = task.TaskManager
+	// TaskChanges are the changes Manager.UpdateTasks makes to every task
+	TaskChanges = task.TaskChanges
+	// Event is a change made to a task, see Manager.Subscribe
+	Event = task.Event
+	// MergeResult summarizes a merge
+	MergeResult = task.MergeResult
+	// MergeConflict describes a task changed on both sides of a merge
+	MergeConflict = task.MergeConflict
+)
+
+// Filters and reports
+type (
+	// Filter selects the tasks that match all of its conditions
+	Filter = task.Filter
+	// ListOptions selects and sorts tasks the way the list command does
+	ListOptions = task.ListOptions
+	// Stats are the counters shown by the stats command
+	Stats = task.Stats
+	// Report is a productivity report for a date range
+	Report = task.Report
+)
+
+// Report intervals
+const (
+	IntervalDay  = task.IntervalDay
+	IntervalWeek = task.IntervalWeek
+)
+
+// Errors
+type (
+	// NotFoundError is returned when there is no task with the given ID
+	NotFoundError = task.NotFoundError
+)
+
+// Errors matched with errors.Is
+var (
+	// ErrNotFound matches the errors of the operations on a task that
+	// doesn't exist
+	ErrNotFound = task.ErrNotFound
+
+	// Invalid values
+	ErrTitleRequired    = task.ErrTitleRequired
+	ErrInvalidPriority  = task.ErrInvalidPriority
+	ErrInvalidDate      = task.ErrInvalidDate
+	ErrInvalidTimeOrder = task.ErrInvalidTimeOrder
+	ErrInvalidDuration  = task.ErrInvalidDuration
+	ErrInvalidFilter    = task.ErrInvalidFilter
+	ErrInvalidID        = task.ErrInvalidID
+	ErrInvalidValue     = task.ErrInvalidValue
+
+	// The state of the task doesn't allow the operation
+	ErrAlreadyCompleted = task.ErrAlreadyCompleted
+	ErrNotCompleted     = task.ErrNotCompleted
+	ErrTimerRunning     = task.ErrTimerRunning
+	ErrNoTimer          = task.ErrNoTimer
+)
+
+// NewTaskManager creates an empty task manager that uses the default data
+// directory
+func NewTaskManager() *TaskManager {
+	return task.NewTaskManager()
+}
+
+// NewTaskManagerAt creates an empty task manager that stores its tasks in dir
+func NewTaskManagerAt(dir string) *TaskManager {
+	return task.NewTaskManagerAt(dir)
+}
+
+// DataDir returns the default data directory, ~/.task-cli
+func DataDir() (string, error) {
+	return task.DataDir()
+}
+
+// ParsePriority parses a priority name: none, someday, low, medium, high,
+// urgent or a name set in the configuration
+func ParsePriority(s string) (TaskPriority, error) {
+	return task.ParsePriority(s)
+}
+
+// ParseDateTime parses a date in any of the formats the CLI accepts, like
+// "2024-01-10 15:00" or "2024-01-10"
+func ParseDateTime(s string) (time.Time, error) {
+	return task.ParseDateTime(s)
+}
+
+// ParseDuration parses a duration, days (d) and weeks (w) included
+func ParseDuration(s string) (time.Duration, error) {
+	return task.ParseDuration(s)
+}
+
+// FormatDuration returns a short representation of a duration like "1h30m"
+func FormatDuration(d time.Duration) string {
+	return task.FormatDuration(d)
+}
+
+// ParseFilter parses a filter expression like "tag:backend priority:high"
+func ParseFilter(expr string) (*Filter, error) {
+	return task.ParseFilter(expr)
+}
+
+// ParseDueFilter parses a time filter like "today", "overdue" or a date
+func ParseDueFilter(s string) (*Filter, error) {
+	return task.ParseDueFilter(s)
+}
+
+// ParseIDs parses a list of IDs and ranges like "3,5,8-12"
+func ParseIDs(s string) ([]int, error) {
+	return task.ParseIDs(s)
+}
+
+// MergeTasks merges two lists of tasks matching them by UID, base is their
+// common version or nil when unknown
+func MergeTasks(base, local, remote []Task) ([]Task, MergeResult) {
+	return task.MergeTasks(base, local, remote)
+}
+
+// ComputeStats computes the statistics of the tasks
+func ComputeStats(tasks []Task) Stats {
+	return task.ComputeStats(tasks)
+}
+
+// BuildReport builds a productivity report for a date range, per day or week
+func BuildReport(tasks []Task, from, to time.Time, interval string) (Report, error) {
+	return task.BuildReport(tasks, from, to, interval)
+}